@@ -0,0 +1,546 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+
+	"github.com/TrueBlocks/goMaker/v6/maker"
+	"github.com/TrueBlocks/goMaker/v6/types"
+)
+
+// exitStale is the exit status for 'generate --check' when generated files are out of
+// date. Other failures exit with status 1.
+const exitStale = 2
+
+type command struct {
+	name  string
+	usage string
+	descr string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"generate", "generate [--single <str>] [--filter <str>] [--remote-testing] [--jobs <n>] [--dry-run] [--check] [--incremental]", "generate all files from the templates (the default)", runGenerate},
+	{"validate", "validate [--suggest]", "load and validate the codebase without generating anything", runValidate},
+	{"diff", "diff [--single <str>] [--filter <str>] [--jobs <n>] [--name-only]", "show a unified diff of what generate would change", runDiff},
+	{"list", "list <routes|types|groups|templates> [--json]", "list the routes, types, groups, or generator templates", runList},
+	{"explain", "explain <file> [--line <n>] [--json]", "report the template, receiver, and inputs that produce a generated file", runExplain},
+	{"eval", "eval [--route|--type|--group|--facet <name>] [<snippet>...]", "render template snippets against a route, type, group, or facet", runEval},
+	{"reference", "reference [--json] [--output <folder>]", "list the functions, fields, and methods templates may call", runReference},
+	{"prune", "prune [--delete] [--force]", "list (or delete) files an earlier run produced that are no longer generated", runPrune},
+	{"watch", "watch [--interval <duration>] [--single <str>] [--filter <str>] [--jobs <n>] [--incremental]", "regenerate affected outputs whenever the templates change", runWatch},
+	{"new", "new type <name> --group <group> [--field <name:type>]...", "create the class definition, fields, and intro for a new data model", runNew},
+	{"new", "new route <route> --group <group> [--option <name:kind>]...", "add a new command to cmd-line-options.csv with its readme intro", runNew},
+}
+
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+func showUsage() {
+	fmt.Println("Usage: goMaker [command] [options]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	for _, cmd := range commands {
+		fmt.Printf("  %-50s %s\n", cmd.usage, cmd.descr)
+	}
+	fmt.Println()
+	fmt.Println("Options for all commands:")
+	fmt.Println("  --config <file>      the project configuration (defaults to ./gomaker.toml)")
+	fmt.Println("  --profile <name>     the profile in the project configuration to use")
+	fmt.Println("  --templates <path>   the templates folder (overrides TB_TEMPLATES_PATH)")
+	fmt.Println("  --generators <path>  the generators folder (overrides TB_GENERATORS_PATH)")
+	fmt.Println("  --overlay <path>     a folder laid over the templates folder (may be repeated)")
+	fmt.Println("  --warnings-as-errors fail if loading the model produces warnings")
+	fmt.Println("  --help, -h           show help information")
+	fmt.Println("  --verbose, -v        show verbose output (or verbose help)")
+	fmt.Println("  --version            show version information")
+}
+
+// commonFlags are accepted by every command. If present, they override the corresponding
+// environment variables which remain as fallbacks.
+// verbose is set by --verbose, which may appear anywhere on the command line
+var verbose bool
+
+type commonFlags struct {
+	config           string
+	profile          string
+	templates        string
+	generators       string
+	overlays         stringList
+	warningsAsErrors bool
+}
+
+func newFlagSet(name string) (*flag.FlagSet, *commonFlags) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	c := &commonFlags{}
+	fs.StringVar(&c.config, "config", "", "the project configuration (defaults to ./gomaker.toml if present)")
+	fs.StringVar(&c.profile, "profile", "", "the profile in the project configuration to use")
+	fs.StringVar(&c.templates, "templates", "", "the templates folder")
+	fs.StringVar(&c.generators, "generators", "", "the generators folder")
+	fs.Var(&c.overlays, "overlay", "a folder laid over the templates folder (may be repeated)")
+	fs.BoolVar(&c.warningsAsErrors, "warnings-as-errors", false, "fail if the model has warnings")
+	return fs, c
+}
+
+func (c *commonFlags) apply(opts *maker.Options) {
+	opts.ConfigFile = c.config
+	opts.Profile = c.profile
+	opts.TemplatesPath = c.templates
+	opts.GeneratorsPath = c.generators
+	opts.Overlays = c.overlays
+	opts.WarningsAsErrors = c.warningsAsErrors
+	opts.Verbose = verbose
+}
+
+// parseArgs parses the flags, which may be interspersed with positional arguments, and
+// returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	positionals := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%s: %w", fs.Name(), err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positionals = append(positionals, args[0])
+		args = args[1:]
+	}
+	return positionals, nil
+}
+
+func noPositionals(name string, positionals []string) error {
+	if len(positionals) > 0 {
+		return fmt.Errorf("%s: unexpected argument '%s'", name, positionals[0])
+	}
+	return nil
+}
+
+// generateFlags limit and tune what generate (and diff and watch) produce.
+type generateFlags struct {
+	single        string
+	filter        string
+	remoteTesting bool
+	jobs          int
+}
+
+func addGenerateFlags(fs *flag.FlagSet) *generateFlags {
+	g := &generateFlags{}
+	fs.StringVar(&g.single, "single", "", "limit processing to templates whose path contains this string")
+	fs.StringVar(&g.filter, "filter", "", "limit generation to generators whose path contains this string")
+	fs.BoolVar(&g.remoteTesting, "remote-testing", false, "do not stop if the codebase has changed")
+	fs.IntVar(&g.jobs, "jobs", runtime.NumCPU(), "the number of files to generate at the same time")
+	return g
+}
+
+func (g *generateFlags) apply(opts *maker.Options) {
+	opts.Single = g.single
+	opts.Filter = g.filter
+	opts.RemoteTesting = g.remoteTesting
+	opts.Jobs = g.jobs
+}
+
+func runGenerate(args []string) error {
+	fs, common := newFlagSet("generate")
+	gen := addGenerateFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "render everything in memory and report what would change")
+	check := fs.Bool("check", false, "render everything in memory and fail if any output is stale")
+	incremental := fs.Bool("incremental", false, "skip outputs whose inputs have not changed since the last run")
+	positionals, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := noPositionals(fs.Name(), positionals); err != nil {
+		return err
+	}
+	opts := maker.Options{DryRun: *dryRun || *check, Incremental: *incremental}
+	common.apply(&opts)
+	gen.apply(&opts)
+
+	generator, err := loadCodebase(opts)
+	if err != nil {
+		return err
+	}
+	if err := generator.Generate(); err != nil {
+		return err
+	}
+
+	if *check {
+		if status := checkStale(os.Stdout, generator.Changes()); status != 0 {
+			os.Exit(status)
+		}
+		return nil
+	}
+
+	if *dryRun {
+		changes := generator.Changes()
+		counts := map[string]int{}
+		for _, change := range changes {
+			counts[change.Status]++
+			if change.Status != "unchanged" {
+				fmt.Printf("%-10s %s\n", change.Status, change.Path)
+			}
+		}
+		fmt.Printf("\n%d created, %d modified, %d unchanged\n\n", counts["created"], counts["modified"], counts["unchanged"])
+		for _, change := range changes {
+			fmt.Print(change.Diff())
+		}
+	}
+	return nil
+}
+
+// checkStale reports the generated files that are out of date and returns the exit status
+// for 'generate --check' (zero if everything is up to date, exitStale otherwise).
+func checkStale(w io.Writer, changes []types.FileChange) int {
+	stale := []string{}
+	for _, change := range changes {
+		if change.Status != "unchanged" {
+			stale = append(stale, change.Path)
+		}
+	}
+	if len(stale) > 0 {
+		fmt.Fprintf(w, "%d generated file(s) are out of date:\n", len(stale))
+		for _, path := range stale {
+			fmt.Fprintln(w, "  "+path)
+		}
+		return exitStale
+	}
+	fmt.Fprintln(w, "All generated files are up to date.")
+	return 0
+}
+
+func runValidate(args []string) error {
+	fs, common := newFlagSet("validate")
+	suggest := fs.Bool("suggest", false, "suggest free letters for colliding hotkeys")
+	positionals, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := noPositionals(fs.Name(), positionals); err != nil {
+		return err
+	}
+	// The model is validated while loading and the validators after. Nothing we do here
+	// writes to disk.
+	opts := maker.Options{DryRun: true, SuggestHotKeys: *suggest}
+	common.apply(&opts)
+
+	generator, err := loadCodebase(opts)
+	if err != nil {
+		return err
+	}
+	codeBase := generator.CodeBase()
+	found, err := codeBase.VerifyValidators()
+	showDiagnostics(found)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Validated %d commands and %d structures.\n", len(codeBase.Commands), len(codeBase.Structures))
+	return nil
+}
+
+func runDiff(args []string) error {
+	fs, common := newFlagSet("diff")
+	gen := addGenerateFlags(fs)
+	nameOnly := fs.Bool("name-only", false, "list the changed files without showing the diffs")
+	positionals, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := noPositionals(fs.Name(), positionals); err != nil {
+		return err
+	}
+	opts := maker.Options{DryRun: true}
+	common.apply(&opts)
+	gen.apply(&opts)
+
+	generator, err := loadCodebase(opts)
+	if err != nil {
+		return err
+	}
+	if err := generator.Generate(); err != nil {
+		return err
+	}
+
+	for _, change := range generator.Changes() {
+		switch {
+		case change.Status == "unchanged":
+			continue
+		case *nameOnly && change.Status == "created":
+			fmt.Println("A", change.Path)
+		case *nameOnly:
+			fmt.Println("M", change.Path)
+		default:
+			fmt.Print(change.Diff())
+		}
+	}
+	return nil
+}
+
+func runList(args []string) error {
+	fs, common := newFlagSet("list")
+	asJson := fs.Bool("json", false, "produce JSON output")
+	positionals, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positionals) != 1 {
+		return fmt.Errorf("list: expected one of routes, types, groups, or templates")
+	}
+	opts := maker.Options{DryRun: true}
+	common.apply(&opts)
+
+	what := positionals[0]
+	items := []string{}
+	if what == "templates" {
+		generators, err := maker.New(opts).Generators()
+		if err != nil {
+			return err
+		}
+		for _, g := range generators {
+			for _, t := range g.Templates {
+				items = append(items, g.Against+"/"+t)
+			}
+		}
+	} else {
+		generator, err := loadCodebase(opts)
+		if err != nil {
+			return err
+		}
+		codeBase := generator.CodeBase()
+		switch what {
+		case "routes":
+			for _, c := range codeBase.Commands {
+				if c.Route != "" {
+					items = append(items, c.Route)
+				}
+			}
+		case "types":
+			for _, st := range codeBase.Structures {
+				items = append(items, st.Class)
+			}
+		case "groups":
+			for _, g := range codeBase.GroupList("") {
+				items = append(items, g.GroupName())
+			}
+		default:
+			return fmt.Errorf("list: unknown list '%s' (expected routes, types, groups, or templates)", what)
+		}
+		sort.Strings(items)
+	}
+
+	if *asJson {
+		return printJson(items)
+	}
+	for _, item := range items {
+		fmt.Println(item)
+	}
+	return nil
+}
+
+// fromLayer describes the template layer that supplied a file (if there are overlays).
+func fromLayer(layer string) string {
+	if layer == "" {
+		return ""
+	}
+	return " (from " + layer + ")"
+}
+
+func runExplain(args []string) error {
+	fs, common := newFlagSet("explain")
+	asJson := fs.Bool("json", false, "produce JSON output")
+	line := fs.Int("line", 0, "report the template line that produced this line of the file")
+	positionals, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positionals) != 1 {
+		return fmt.Errorf("explain: expected the path of a generated file")
+	}
+	opts := maker.Options{DryRun: true}
+	common.apply(&opts)
+
+	generator, err := loadCodebase(opts)
+	if err != nil {
+		return err
+	}
+
+	outputs, err := generator.CodeBase().Explain(positionals[0])
+	if err != nil {
+		return err
+	}
+	if len(outputs) == 0 {
+		return fmt.Errorf("explain: no template produces %s", positionals[0])
+	}
+
+	type explained struct {
+		types.Output
+		Line *types.LineSource `json:"lineSource,omitempty"`
+	}
+	results := []explained{}
+	for _, o := range outputs {
+		result := explained{Output: o}
+		if *line > 0 {
+			if result.Line, err = generator.CodeBase().ExplainLine(o, *line); err != nil {
+				return err
+			}
+		}
+		results = append(results, result)
+	}
+
+	if *asJson {
+		return printJson(results)
+	}
+	for _, r := range results {
+		fmt.Println("File:    ", r.Path)
+		fmt.Println("Template:", r.Template+fromLayer(r.Layer(r.Template)))
+		fmt.Println("Scope:   ", r.Scope)
+		fmt.Println("Receiver:", r.Receiver())
+		for i, input := range r.Inputs {
+			label := "Inputs:  "
+			if i > 0 {
+				label = "         "
+			}
+			fmt.Println(label, input+fromLayer(r.Layer(input)))
+		}
+		if r.Line != nil {
+			fmt.Println()
+			fmt.Printf("Line %d:  %s\n", r.Line.Line, r.Line.Text)
+			switch {
+			case r.Line.TemplateLine == 0:
+				fmt.Println("was not produced by the template (it may be inside an EXISTING_CODE block)")
+			case r.Line.Exact:
+				fmt.Printf("comes from %s:%d\n", types.RelativePath(r.Line.Template), r.Line.TemplateLine)
+			default:
+				fmt.Println("was not rendered as-is by the template (it may be inside an EXISTING_CODE block or reformatted).")
+				fmt.Printf("The closest preceding line comes from %s:%d\n", types.RelativePath(r.Line.Template), r.Line.TemplateLine)
+			}
+			if r.Line.TemplateLine > 0 {
+				fmt.Printf("  %d | %s\n", r.Line.TemplateLine, r.Line.Source)
+			}
+		}
+	}
+	return nil
+}
+
+func runPrune(args []string) error {
+	fs, common := newFlagSet("prune")
+	doDelete := fs.Bool("delete", false, "delete the orphaned files")
+	force := fs.Bool("force", false, "also delete orphaned files that were edited after goMaker wrote them")
+	positionals, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := noPositionals(fs.Name(), positionals); err != nil {
+		return err
+	}
+	opts := maker.Options{DryRun: true}
+	common.apply(&opts)
+
+	generator, err := loadCodebase(opts)
+	if err != nil {
+		return err
+	}
+
+	orphans, err := generator.CodeBase().Orphans()
+	if err != nil {
+		return err
+	}
+	if len(orphans) == 0 {
+		fmt.Println("No orphaned files.")
+		return nil
+	}
+
+	toDelete := []types.ManifestEntry{}
+	for _, orphan := range orphans {
+		note := ""
+		if orphan.IsModified() {
+			note = " (modified since it was generated)"
+		}
+		fmt.Printf("%s%s\n", orphan.Path, note)
+		if *doDelete && (note == "" || *force) {
+			toDelete = append(toDelete, orphan)
+		}
+	}
+
+	if !*doDelete {
+		fmt.Printf("\n%d orphaned file(s). Use --delete to remove them.\n", len(orphans))
+		return nil
+	}
+
+	if err := types.RemoveOrphans(toDelete); err != nil {
+		return err
+	}
+	fmt.Printf("\nDeleted %d of %d orphaned file(s).\n", len(toDelete), len(orphans))
+	if len(toDelete) < len(orphans) {
+		fmt.Println("Modified files were kept. Use --force to delete them too.")
+	}
+	return nil
+}
+
+func runReference(args []string) error {
+	fs, _ := newFlagSet("reference")
+	asJson := fs.Bool("json", false, "produce JSON output")
+	output := fs.String("output", "", "write template-reference.md and template-reference.json to this folder")
+	positionals, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := noPositionals(fs.Name(), positionals); err != nil {
+		return err
+	}
+
+	ref, err := types.GetReference()
+	if err != nil {
+		return err
+	}
+
+	if *output != "" {
+		bytes, err := json.MarshalIndent(ref, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(*output, 0755); err != nil {
+			return err
+		}
+		mdPath := filepath.Join(*output, "template-reference.md")
+		jsonPath := filepath.Join(*output, "template-reference.json")
+		if err := os.WriteFile(mdPath, []byte(ref.Markdown()), 0644); err != nil {
+			return err
+		}
+		if err := os.WriteFile(jsonPath, append(bytes, '\n'), 0644); err != nil {
+			return err
+		}
+		fmt.Println("Wrote", mdPath)
+		fmt.Println("Wrote", jsonPath)
+		return nil
+	}
+
+	if *asJson {
+		return printJson(ref)
+	}
+	fmt.Print(ref.Markdown())
+	return nil
+}
+
+func printJson(v any) error {
+	bytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(bytes))
+	return nil
+}
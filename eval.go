@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/TrueBlocks/goMaker/v6/maker"
+	"github.com/TrueBlocks/goMaker/v6/types"
+)
+
+func runEval(args []string) error {
+	fs, common := newFlagSet("eval")
+	var receiver types.EvalReceiver
+	fs.StringVar(&receiver.Route, "route", "", "apply the snippets to this route")
+	fs.StringVar(&receiver.Type, "type", "", "apply the snippets to this type")
+	fs.StringVar(&receiver.Group, "group", "", "apply the snippets to the codebase for this group")
+	fs.StringVar(&receiver.Reason, "reason", "", "for --group, readme (the default) or model")
+	fs.StringVar(&receiver.Facet, "facet", "", "apply the snippets to this facet (Type.Facet)")
+
+	snippets, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	// Loading writes nothing
+	opts := maker.Options{DryRun: true, RemoteTesting: true}
+	common.apply(&opts)
+	generator, err := loadCodebase(opts)
+	if err != nil {
+		return err
+	}
+	cb := generator.CodeBase()
+
+	evaluator, err := cb.NewEvaluator(receiver)
+	if err != nil {
+		return fmt.Errorf("eval: %w", err)
+	}
+
+	// Snippets on the command line are evaluated once each
+	if len(snippets) > 0 {
+		for _, snippet := range snippets {
+			result, err := evaluator.Eval(snippet)
+			if err != nil {
+				return fmt.Errorf("eval: %w", err)
+			}
+			fmt.Print(withNewline(result))
+		}
+		return nil
+	}
+
+	// Piped input is a single snippet
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice == 0 {
+		snippet, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		result, err := evaluator.Eval(string(snippet))
+		if err != nil {
+			return fmt.Errorf("eval: %w", err)
+		}
+		fmt.Print(result)
+		return nil
+	}
+
+	return evalLoop(cb, evaluator, os.Stdin, os.Stdout)
+}
+
+const evalHelp = `Enter a template snippet to render it. End a line with \ to continue the snippet
+on the next line. Commands:
+  :route <route>        apply snippets to a route
+  :type <type>          apply snippets to a type
+  :group <group> [model] apply snippets to the codebase for a group
+  :facet <Type.Facet>   apply snippets to a facet
+  :codebase             apply snippets to the codebase
+  :help                 show this help
+  :quit                 exit (as does end of file)`
+
+// evalLoop reads snippets and commands from in until end of file or :quit. Errors are
+// shown inline and do not end the loop.
+func evalLoop(cb *types.CodeBase, evaluator *types.Evaluator, in io.Reader, out io.Writer) error {
+	fmt.Fprintln(out, "Type :help for help.")
+	scanner := bufio.NewScanner(in)
+	prompt := func() {
+		fmt.Fprintf(out, "%s> ", evaluator)
+	}
+
+	prompt()
+	pending := []string{}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasSuffix(line, "\\") {
+			pending = append(pending, strings.TrimSuffix(line, "\\"))
+			fmt.Fprint(out, "... ")
+			continue
+		}
+		snippet := strings.Join(append(pending, line), "\n")
+		pending = pending[:0]
+
+		if fields := strings.Fields(snippet); len(fields) > 0 && strings.HasPrefix(fields[0], ":") {
+			var receiver types.EvalReceiver
+			arg := ""
+			if len(fields) > 1 {
+				arg = fields[1]
+			}
+			switch fields[0] {
+			case ":quit", ":q", ":exit":
+				return nil
+			case ":help", ":h":
+				fmt.Fprintln(out, evalHelp)
+				prompt()
+				continue
+			case ":route":
+				receiver.Route = arg
+			case ":type":
+				receiver.Type = arg
+			case ":group":
+				receiver.Group = arg
+				if len(fields) > 2 {
+					receiver.Reason = fields[2]
+				}
+			case ":facet":
+				receiver.Facet = arg
+			case ":codebase":
+			default:
+				fmt.Fprintf(out, "unknown command %s (type :help for help)\n", fields[0])
+				prompt()
+				continue
+			}
+			if next, err := cb.NewEvaluator(receiver); err != nil {
+				fmt.Fprintln(out, "error:", err)
+			} else {
+				evaluator = next
+			}
+			prompt()
+			continue
+		}
+
+		if strings.TrimSpace(snippet) != "" {
+			if result, err := evaluator.Eval(snippet); err != nil {
+				fmt.Fprintln(out, "error:", err)
+			} else {
+				fmt.Fprint(out, withNewline(result))
+			}
+		}
+		prompt()
+	}
+	fmt.Fprintln(out)
+	return scanner.Err()
+}
+
+func withNewline(s string) string {
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		return s + "\n"
+	}
+	return s
+}
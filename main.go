@@ -2,11 +2,13 @@ package main
 
 import (
 	"embed"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 
+	"github.com/TrueBlocks/goMaker/v6/maker"
 	"github.com/TrueBlocks/goMaker/v6/types"
 	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/logger"
 )
@@ -41,21 +43,27 @@ func showHelp() {
 	fmt.Println(string(helpContent))
 }
 
-func showVersion() {
+func getVersion() (string, error) {
 	versionFile, err := helpFS.Open("VERSION")
 	if err != nil {
-		fmt.Println("Error: Could not load version information.")
-		return
+		return "", fmt.Errorf("could not load version information")
 	}
 	defer versionFile.Close()
 
 	versionContent, err := io.ReadAll(versionFile)
 	if err != nil {
-		fmt.Println("Error: Could not read version information.")
-		return
+		return "", fmt.Errorf("could not read version information")
 	}
 
-	version := strings.TrimSpace(string(versionContent))
+	return strings.TrimSpace(string(versionContent)), nil
+}
+
+func showVersion() {
+	version, err := getVersion()
+	if err != nil {
+		fmt.Println("Error: " + strings.ToUpper(err.Error()[:1]) + err.Error()[1:] + ".")
+		return
+	}
 	fmt.Println("Version:  v" + version)
 	fmt.Println("Compiled:", compileTime)
 }
@@ -64,26 +72,19 @@ func main() {
 	showHelpFlag := false
 	showVersionFlag := false
 
-	// Validate all arguments first
-	for i, arg := range os.Args {
-		if i == 0 { // Skip program name
-			continue
-		}
-
+	// Global options may appear anywhere on the command line
+	args := []string{}
+	for _, arg := range os.Args[1:] {
 		switch arg {
 		case "--help", "-h", "-help", "help":
 			showHelpFlag = true
 		case "--verbose", "-v", "-verbose":
+			verbose = true
 			types.SetVerbose(true)
 		case "--version":
 			showVersionFlag = true
 		default:
-			fmt.Printf("Error: Unknown option '%s'\n\n", arg)
-			fmt.Println("Valid options:")
-			fmt.Println("  --help, -h     Show help information")
-			fmt.Println("  --verbose, -v  Show verbose help information")
-			fmt.Println("  --version      Show version information")
-			os.Exit(1)
+			args = append(args, arg)
 		}
 	}
 
@@ -97,29 +98,71 @@ func main() {
 		return
 	}
 
-	// Normal execution
-	pwd, _ := os.Getwd()
-	logger.InfoBY("Current folder:", pwd)
+	if version, err := getVersion(); err == nil {
+		types.SetVersion(version + " " + compileTime)
+	}
+
+	// With no command, we generate (as we always have)
+	name := "generate"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name = args[0]
+		args = args[1:]
+	}
 
-	// First check if templates folder exists and isn't empty
-	if err := types.ValidateTemplatesFolder(); err != nil {
+	cmd := findCommand(name)
+	if cmd == nil {
+		fmt.Printf("Error: Unknown command '%s'\n\n", name)
+		showUsage()
+		os.Exit(1)
+	}
+
+	if err := cmd.run(args); err != nil {
 		fmt.Println("Error:", err)
-		fmt.Println("\nHere are the requirements to run goMaker:")
-		types.SetVerbose(false)
-		showHelp()
 		os.Exit(1)
 	}
+}
+
+// loadCodebase loads the codebase from the templates folder, showing the requirements
+// for running goMaker if the folder cannot be found.
+func loadCodebase(opts maker.Options) (*maker.Generator, error) {
+	pwd, _ := os.Getwd()
+	logger.InfoBY("Current folder:", pwd)
 
-	codeBase, err := types.LoadCodebase()
+	gen := maker.New(opts)
+	err := gen.Load()
+	if cfg := types.GetProjectConfig(); cfg != nil && err == nil {
+		if cfg.Profile() != "" {
+			logger.InfoBY("Configuration:", cfg.Path(), "(profile "+cfg.Profile()+")")
+		} else {
+			logger.InfoBY("Configuration:", cfg.Path())
+		}
+	}
+	showDiagnostics(gen.Diagnostics())
 	if err != nil {
-		if strings.Contains(err.Error(), "could not find the templates directory") {
-			fmt.Println("Error:", err)
-			fmt.Println("\nHere are the requirements to run goMaker:")
-			types.SetVerbose(false)
-			showHelp()
-			os.Exit(1)
+		var e *maker.Error
+		if errors.As(err, &e) && e.Op == "find" {
+			showRequirements(e.Err)
+		}
+		return nil, err
+	}
+	return gen, nil
+}
+
+// showDiagnostics reports every problem found in the model.
+func showDiagnostics(diagnostics []types.Diagnostic) {
+	for _, d := range diagnostics {
+		if d.Severity == types.SeverityError {
+			logger.Error(d.String())
+		} else {
+			logger.Warn(d.String())
 		}
-		logger.Fatal(err)
 	}
-	codeBase.Generate()
+}
+
+func showRequirements(err error) {
+	fmt.Println("Error:", err)
+	fmt.Println("\nHere are the requirements to run goMaker:")
+	types.SetVerbose(false)
+	showHelp()
+	os.Exit(1)
 }
@@ -1,10 +1,149 @@
 package main
 
 import (
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/TrueBlocks/goMaker/v6/maker"
+	"github.com/TrueBlocks/goMaker/v6/types"
 )
 
 func TestMainFunction(t *testing.T) {
 	// os.Chdir("/Users/jrush/Development/trueblocks-dalledress")
 	// main()
 }
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		positionals []string
+		opts        maker.Options
+		err         string
+	}{
+		{
+			name:        "no arguments",
+			positionals: []string{},
+			opts:        maker.Options{Jobs: 4},
+		},
+		{
+			name:        "common and generate flags",
+			args:        []string{"--templates", "/t/templates", "--generators=/t/generators", "--single", "blocks", "--filter", "types", "--remote-testing", "--jobs", "2"},
+			positionals: []string{},
+			opts:        maker.Options{TemplatesPath: "/t/templates", GeneratorsPath: "/t/generators", Single: "blocks", Filter: "types", RemoteTesting: true, Jobs: 2},
+		},
+		{
+			name:        "flags between positionals",
+			args:        []string{"routes", "--config", "my.toml", "extra", "--profile", "core", "--warnings-as-errors"},
+			positionals: []string{"routes", "extra"},
+			opts:        maker.Options{ConfigFile: "my.toml", Profile: "core", WarningsAsErrors: true, Jobs: 4},
+		},
+		{
+			name:        "repeated and comma separated overlays",
+			args:        []string{"--overlay", "a,b", "--overlay", "c"},
+			positionals: []string{},
+			opts:        maker.Options{Overlays: []string{"a", "b", "c"}, Jobs: 4},
+		},
+		{
+			name:        "the last of a repeated flag wins",
+			args:        []string{"--single", "one", "--single", "two"},
+			positionals: []string{},
+			opts:        maker.Options{Single: "two", Jobs: 4},
+		},
+		{
+			name: "unknown flag",
+			args: []string{"--nope"},
+			err:  "test: flag provided but not defined: -nope",
+		},
+		{
+			name: "missing value",
+			args: []string{"--templates"},
+			err:  "test: flag needs an argument: -templates",
+		},
+		{
+			name: "bad value",
+			args: []string{"--jobs", "many"},
+			err:  "test: invalid value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs, common := newFlagSet("test")
+			gen := addGenerateFlags(fs)
+			gen.jobs = 4
+			positionals, err := parseArgs(fs, tt.args)
+			if tt.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(positionals, tt.positionals) {
+				t.Errorf("got positionals %q, want %q", positionals, tt.positionals)
+			}
+			opts := maker.Options{}
+			common.apply(&opts)
+			gen.apply(&opts)
+			if !reflect.DeepEqual(opts, tt.opts) {
+				t.Errorf("got options %+v, want %+v", opts, tt.opts)
+			}
+		})
+	}
+}
+
+func TestNoPositionals(t *testing.T) {
+	if err := noPositionals("validate", []string{}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := noPositionals("validate", []string{"x"}); err == nil || err.Error() != "validate: unexpected argument 'x'" {
+		t.Errorf("expected an unexpected argument error, got %v", err)
+	}
+}
+
+func TestFindCommand(t *testing.T) {
+	for _, name := range []string{"generate", "validate", "diff", "list", "explain"} {
+		if cmd := findCommand(name); cmd == nil || cmd.name != name {
+			t.Errorf("command %s not found", name)
+		}
+	}
+	if cmd := findCommand("nope"); cmd != nil {
+		t.Errorf("found unknown command %s", cmd.name)
+	}
+}
+
+func TestCheckStale(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []types.FileChange
+		status  int
+		output  string
+	}{
+		{"nothing generated", nil, 0, "All generated files are up to date.\n"},
+		{"up to date", []types.FileChange{{Path: "a.go", Status: "unchanged"}}, 0, "All generated files are up to date.\n"},
+		{
+			name:    "stale",
+			changes: []types.FileChange{{Path: "a.go", Status: "unchanged"}, {Path: "b.go", Status: "modified"}, {Path: "c.md", Status: "created"}},
+			status:  exitStale,
+			output:  "2 generated file(s) are out of date:\n  b.go\n  c.md\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			if status := checkStale(&out, tt.changes); status != tt.status {
+				t.Errorf("got status %d, want %d", status, tt.status)
+			}
+			if out.String() != tt.output {
+				t.Errorf("got output %q, want %q", out.String(), tt.output)
+			}
+		})
+	}
+	if exitStale == 1 {
+		t.Error("exitStale must differ from the status of other errors")
+	}
+}
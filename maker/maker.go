@@ -0,0 +1,193 @@
+// Package maker lets other Go programs (tests, editors, CI tools) load a codebase and
+// generate code from its templates without running the goMaker binary.
+//
+// Errors are returned, never fatal. Errors from loading or generating are of type *Error
+// which reports what was being done and, if known, the template being processed.
+//
+// The generator keeps its settings and caches in package-level state, so a process may use
+// only one Generator at a time.
+package maker
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+
+	"github.com/TrueBlocks/goMaker/v6/types"
+	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/logger"
+)
+
+// Error is the error returned by Load and Generate.
+type Error = types.Error
+
+// Targets limits what GenerateOnly produces.
+type Targets = types.Targets
+
+// Options configures a Generator. Empty (or false) options fall back to the environment
+// variables the goMaker binary reads (TB_TEMPLATES_PATH, TB_GENERATORS_PATH, and so on)
+// and then to the project's gomaker.toml.
+type Options struct {
+	// ConfigFile is the project configuration. If empty, gomaker.toml is used if it
+	// exists in OutputRoot (or the current working directory).
+	ConfigFile string
+	// Profile is the profile in the configuration to use. If empty, TB_MAKER_PROFILE or
+	// the configuration's default_profile is used.
+	Profile string
+	// TemplatesPath is the templates folder (it must end with 'templates').
+	TemplatesPath string
+	// GeneratorsPath is the generators folder (it must end with 'generators').
+	GeneratorsPath string
+	// Overlays are folders laid over the templates folder in order. A file in an overlay
+	// replaces the file with the same relative path in the templates folder (or in an
+	// earlier overlay). If nil, TB_TEMPLATES_OVERLAYS or the configuration's overlays are used.
+	Overlays []string
+	// Single limits processing to templates whose path contains this string.
+	Single string
+	// Filter limits generation to generators whose path contains this string.
+	Filter string
+	// OutputRoot is the folder generated paths are relative to. Defaults to the
+	// current working directory.
+	OutputRoot string
+	// Writer receives progress messages. If nil, they go to os.Stderr.
+	Writer io.Writer
+	// RemoteTesting disables the check that stops generation if the codebase changed.
+	RemoteTesting bool
+	// DryRun renders everything in memory. Nothing is written. See Changes.
+	DryRun bool
+	// Incremental skips outputs whose inputs have not changed since the last run.
+	Incremental bool
+	// Jobs is the number of files generated at the same time. Zero means one per CPU.
+	Jobs int
+	// Verbose turns on verbose logging. If false, TB_VERBOSE is used.
+	Verbose bool
+	// WarningsAsErrors makes Load fail if the model has warnings, not only errors.
+	WarningsAsErrors bool
+	// SuggestHotKeys adds letters that are free to the diagnostics about hotkey collisions.
+	SuggestHotKeys bool
+}
+
+// Generator loads a codebase and generates code from it.
+type Generator struct {
+	opts     Options
+	codeBase types.CodeBase
+	loaded   bool
+}
+
+// New returns a Generator configured with the given options.
+func New(opts Options) *Generator {
+	return &Generator{opts: opts}
+}
+
+// apply pushes the options (and the project configuration) into the types package.
+func (g *Generator) apply() error {
+	configFile := g.opts.ConfigFile
+	if configFile == "" {
+		configFile = types.FindProjectConfig(g.opts.OutputRoot)
+	}
+	var cfg *types.ProjectConfig
+	if configFile != "" {
+		var err error
+		if cfg, err = types.LoadProjectConfig(configFile, g.opts.Profile); err != nil {
+			return &Error{Op: "config", Path: configFile, Err: err}
+		}
+	} else if g.opts.Profile != "" {
+		return &Error{Op: "config", Err: fmt.Errorf("profile %s given but there is no %s", g.opts.Profile, types.ProjectConfigFile)}
+	}
+	types.SetProjectConfig(cfg)
+
+	types.SetTemplatesPath(g.opts.TemplatesPath)
+	types.SetGeneratorsPath(g.opts.GeneratorsPath)
+	types.SetTemplateOverlays(g.opts.Overlays)
+	types.SetSingle(g.opts.Single)
+	types.SetFilter(g.opts.Filter)
+	types.SetOutputRoot(g.opts.OutputRoot)
+	types.SetRemoteTesting(g.opts.RemoteTesting)
+	types.SetDryRun(g.opts.DryRun)
+	types.SetIncremental(g.opts.Incremental)
+	types.SetWarningsAsErrors(g.opts.WarningsAsErrors)
+	types.SetSuggestHotKeys(g.opts.SuggestHotKeys)
+	// Every setting is applied (even if it is not set) so a Generator does not inherit
+	// the settings of an earlier one
+	jobs := g.opts.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	types.SetWorkers(jobs)
+	types.SetVerbose(g.opts.Verbose || os.Getenv("TB_VERBOSE") == "true")
+	var writer io.Writer = os.Stderr
+	if g.opts.Writer != nil {
+		writer = g.opts.Writer
+	}
+	logger.SetLoggerWriter(writer)
+	return nil
+}
+
+// Load reads and validates the class definitions and command line options. Every problem
+// in the model is reported (see Diagnostics) before Load fails. If the
+// templates folder cannot be found (or is empty), the returned error's Op is "find". If
+// the project configuration cannot be read (or names an overlay that does not exist), it
+// is "config".
+func (g *Generator) Load() error {
+	if err := g.apply(); err != nil {
+		return err
+	}
+	types.ResetTemplateCache()
+	types.ResetPendingChanges()
+	types.ResetDiagnostics()
+	if err := types.ValidateTemplatesFolder(); err != nil {
+		return &Error{Op: "find", Path: g.opts.TemplatesPath, Err: err}
+	}
+	if err := types.ValidateOverlays(); err != nil {
+		return &Error{Op: "config", Err: err}
+	}
+	codeBase, err := types.LoadCodebase()
+	if err != nil {
+		return err
+	}
+	g.codeBase = codeBase
+	g.loaded = true
+	return nil
+}
+
+// Diagnostics returns the errors and warnings found by the last Load, whether it
+// succeeded or not.
+func (g *Generator) Diagnostics() []types.Diagnostic {
+	return types.Diagnostics()
+}
+
+// CodeBase returns the loaded codebase.
+func (g *Generator) CodeBase() *types.CodeBase {
+	return &g.codeBase
+}
+
+// Generate generates every output, loading the codebase first if needed.
+func (g *Generator) Generate() error {
+	return g.GenerateOnly(nil)
+}
+
+// GenerateOnly generates the outputs selected by targets (everything if targets is nil).
+func (g *Generator) GenerateOnly(targets *Targets) error {
+	if !g.loaded {
+		if err := g.Load(); err != nil {
+			return err
+		}
+	} else if err := g.apply(); err != nil {
+		return err
+	}
+	return g.codeBase.GenerateOnly(targets)
+}
+
+// Generators returns the generator templates that Generate would use.
+func (g *Generator) Generators() ([]types.Generator, error) {
+	if err := g.apply(); err != nil {
+		return nil, err
+	}
+	return types.Generators()
+}
+
+// Changes returns the outputs rendered during a dry run and whether each would be
+// created, modified, or left unchanged.
+func (g *Generator) Changes() []types.FileChange {
+	return types.PendingChanges()
+}
@@ -0,0 +1,201 @@
+package maker
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/goMaker/v6/types"
+	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/logger"
+)
+
+// copyTemplates copies the repository's templates folder into dir and returns the copy's
+// path.
+func copyTemplates(t *testing.T, dir string) string {
+	t.Helper()
+	src, err := filepath.Abs("../templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(dir, "templates")
+	err = filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0o755)
+		}
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), contents, 0o644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dst
+}
+
+// newGenerator returns a Generator for a copy of the templates folder in a temporary
+// output root, along with the output root.
+func newGenerator(t *testing.T, opts Options) (*Generator, string) {
+	t.Helper()
+	dir := t.TempDir()
+	if opts.TemplatesPath == "" {
+		opts.TemplatesPath = copyTemplates(t, dir)
+		opts.GeneratorsPath = filepath.Join(opts.TemplatesPath, "generators")
+	}
+	opts.OutputRoot = dir
+	t.Cleanup(func() {
+		_ = New(Options{}).apply()
+		types.ResetPendingChanges()
+		types.ResetDiagnostics()
+	})
+	return New(opts), dir
+}
+
+func TestLoadErrors(t *testing.T) {
+	badProfile := filepath.Join(t.TempDir(), types.ProjectConfigFile)
+	if err := os.WriteFile(badProfile, []byte("[profiles.core.paths]\ntemplates = \"templates\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TB_MAKER_PROFILE", "")
+
+	tests := []struct {
+		name string
+		opts Options
+		op   string
+	}{
+		{"missing templates", Options{TemplatesPath: filepath.Join(t.TempDir(), "templates")}, "find"},
+		{"unknown profile", Options{ConfigFile: badProfile, Profile: "nope"}, "config"},
+		{"profile without a config", Options{Profile: "core"}, "config"},
+		{"missing overlay", Options{Overlays: []string{"no-such-overlay"}}, "config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := newGenerator(t, tt.opts)
+			err := g.Load()
+			var makerErr *Error
+			if !errors.As(err, &makerErr) {
+				t.Fatalf("expected a *maker.Error, got %T: %v", err, err)
+			}
+			if makerErr.Op != tt.op {
+				t.Errorf("got Op %q, want %q (%v)", makerErr.Op, tt.op, err)
+			}
+		})
+	}
+}
+
+func TestDiagnosticsAfterFailedLoad(t *testing.T) {
+	g, _ := newGenerator(t, Options{})
+	block := filepath.Join(g.opts.TemplatesPath, "classDefinitions", "block.toml")
+	contents, err := os.ReadFile(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := strings.Replace(string(contents), `cache_type = "cacheable"`, `cache_type = "bogus"`, 1)
+	if err := os.WriteFile(block, []byte(broken), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.Load(); err == nil {
+		t.Fatal("expected the load to fail")
+	}
+	found := false
+	for _, d := range g.Diagnostics() {
+		if d.Severity == types.SeverityError && d.File == block && d.Key == "settings.cache_type" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected an error about block.toml's cache_type, got %v", g.Diagnostics())
+	}
+}
+
+func TestDryRunWritesNothing(t *testing.T) {
+	g, root := newGenerator(t, Options{DryRun: true, RemoteTesting: true})
+	if err := os.MkdirAll(filepath.Join(root, "generated"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	listFiles := func() map[string]string {
+		ret := map[string]string{}
+		_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				contents, _ := os.ReadFile(path)
+				ret[path] = string(contents)
+			}
+			return nil
+		})
+		return ret
+	}
+
+	if err := g.Load(); err != nil {
+		t.Fatal(err)
+	}
+	// The repository's templates do not have an intro for every model
+	for _, s := range g.CodeBase().Structures {
+		intro := filepath.Join(g.opts.TemplatesPath, "model-intros", types.CamelCase(s.Class)+".md")
+		if _, err := os.Stat(intro); err != nil {
+			if err := os.WriteFile(intro, []byte(s.Class+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	before := listFiles()
+
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	changes := g.Changes()
+	if len(changes) == 0 {
+		t.Fatal("expected the dry run to report changes")
+	}
+	for _, change := range changes {
+		if change.Status != "created" || change.New == "" {
+			t.Errorf("%s: got status %s, want created with its contents", change.Path, change.Status)
+		}
+	}
+
+	after := listFiles()
+	if len(after) != len(before) {
+		t.Errorf("the dry run changed the number of files from %d to %d", len(before), len(after))
+	}
+	for path, contents := range after {
+		if before[path] != contents {
+			t.Errorf("the dry run wrote %s", path)
+		}
+	}
+}
+
+func TestGeneratorsDoNotShareSettings(t *testing.T) {
+	t.Setenv("TB_VERBOSE", "")
+	var log bytes.Buffer
+	first, _ := newGenerator(t, Options{Jobs: 3, Verbose: true, Writer: &log})
+	if err := first.apply(); err != nil {
+		t.Fatal(err)
+	}
+	if types.GetWorkers() != 3 || !types.IsVerbose() || logger.GetLoggerWriter() != &log {
+		t.Fatal("the first generator's settings were not applied")
+	}
+
+	second, _ := newGenerator(t, Options{})
+	if err := second.apply(); err != nil {
+		t.Fatal(err)
+	}
+	if types.GetWorkers() != runtime.NumCPU() {
+		t.Errorf("got %d workers, want one per CPU", types.GetWorkers())
+	}
+	if types.IsVerbose() {
+		t.Error("the second generator inherited verbose logging")
+	}
+	if logger.GetLoggerWriter() != os.Stderr {
+		t.Error("the second generator inherited the first one's writer")
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TrueBlocks/goMaker/v6/maker"
+	"github.com/TrueBlocks/goMaker/v6/types"
+)
+
+// stringList is a flag that may be repeated. Each value may also be a comma separated list.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*s = append(*s, v)
+		}
+	}
+	return nil
+}
+
+func runNew(args []string) error {
+	if len(args) == 0 || (args[0] != "type" && args[0] != "route") {
+		return fmt.Errorf("new: expected 'type' or 'route'")
+	}
+	what := args[0]
+
+	fs, common := newFlagSet("new " + what)
+	group := fs.String("group", "", "the group the new type or route belongs to")
+	descr := fs.String("descr", "", "the description")
+	// new type
+	producedBy := fs.String("produced-by", "", "the routes that produce the type (comma separated)")
+	var fields stringList
+	fs.Var(&fields, "field", "a field of the type as name:type (may be repeated)")
+	// new route
+	folder := fs.String("folder", "tools", "the folder holding the route's source (apps or tools)")
+	tool := fs.String("tool", "", "the name of the tool (defaults to the route)")
+	summary := fs.String("summary", "", "the route's summary")
+	returns := fs.String("returns", "", "the type the route produces")
+	var options stringList
+	fs.Var(&options, "option", "an option of the route as name:kind[:data_type] (may be repeated)")
+
+	positionals, err := parseArgs(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(positionals) != 1 {
+		return fmt.Errorf("%s: expected the name of the new %s", fs.Name(), what)
+	}
+	if *group == "" {
+		return fmt.Errorf("%s: --group is required", fs.Name())
+	}
+
+	// Loading writes nothing. We only need the codebase to find the next free numbers.
+	opts := maker.Options{DryRun: true, RemoteTesting: true}
+	common.apply(&opts)
+	generator, err := loadCodebase(opts)
+	if err != nil {
+		return err
+	}
+
+	var scaffold *types.Scaffold
+	if what == "type" {
+		scaffold, err = generator.CodeBase().NewType(types.NewTypeOptions{
+			Name:        positionals[0],
+			Group:       *group,
+			Description: *descr,
+			ProducedBy:  *producedBy,
+			Fields:      fields,
+		})
+	} else {
+		scaffold, err = generator.CodeBase().NewRoute(types.NewRouteOptions{
+			Route:       positionals[0],
+			Group:       *group,
+			Folder:      *folder,
+			Tool:        *tool,
+			Summary:     *summary,
+			Description: *descr,
+			Returns:     *returns,
+			Options:     options,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Name(), err)
+	}
+
+	// Make sure what we wrote loads cleanly. If it does not, put things back as they were.
+	err = generator.Load()
+	showDiagnostics(generator.Diagnostics())
+	if err != nil {
+		if undoErr := scaffold.Undo(); undoErr != nil {
+			return fmt.Errorf("%s: %w (and could not undo the changes: %v)", fs.Name(), err, undoErr)
+		}
+		return fmt.Errorf("%s: the new %s does not validate, nothing was changed: %w", fs.Name(), what, err)
+	}
+
+	for _, path := range scaffold.Created {
+		fmt.Println("Created: ", path)
+	}
+	for _, path := range scaffold.Modified {
+		fmt.Println("Modified:", path)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package types
+
+import (
+	"sort"
+	"strings"
+)
+
+// cacheKey is a member a cached structure's id is built from (see CacheIdStr).
+type cacheKey struct {
+	name  string   // the member's name
+	types []string // the member types the id may be built from
+}
+
+var (
+	addressKey = func(name string) cacheKey { return cacheKey{name, []string{"address"}} }
+	blockKey   = cacheKey{"blockNumber", []string{"blknum", "uint64"}}
+	txKey      = cacheKey{"transactionIndex", []string{"txnum", "uint64"}}
+)
+
+// cacheKeys are the members each cache_by builds the cache id from. They must match the
+// fields CacheIdStr uses.
+var cacheKeys = map[string][]cacheKey{
+	"address":                       {addressKey("address")},
+	"address,block":                 {addressKey("address"), blockKey},
+	"address,fourbyte,block":        {addressKey("address"), {"encoding", []string{"string"}}, blockKey},
+	"address,tx":                    {addressKey("address"), blockKey, txKey},
+	"address,address,block":         {addressKey("holder"), addressKey("address"), blockKey},
+	"address,address,address,block": {addressKey("owner"), addressKey("token"), addressKey("spender"), blockKey},
+	"statement":                     {addressKey("holder"), addressKey("asset"), blockKey, txKey},
+	"block":                         {blockKey},
+	"tx":                            {blockKey, txKey},
+	"filename":                      {{"filename", []string{"string"}}},
+}
+
+// checkCache reports cache settings the generated code could not be built from. A cacheable
+// structure must have a known cache_by and the members it implies. Those members may not be
+// calculated or removed and must have a type the cache id can be built from. Structures
+// cached as a group are not checked against their members because the group (which is
+// generated) holds the fields the id is built from.
+func (s *Structure) checkCache() {
+	switch s.CacheType {
+	case "", "marshal_only":
+		if s.CacheBy != "" {
+			reportError(s.pos.withKey("settings.cache_by"), "structure %s has a cache_by but its cache_type is not cacheable", s.Class)
+		}
+		if s.CacheAs != "" {
+			reportError(s.pos.withKey("settings.cache_as"), "structure %s has a cache_as but its cache_type is not cacheable", s.Class)
+		}
+		return
+	case "cacheable":
+	default:
+		reportError(s.pos.withKey("settings.cache_type"), "unknown cache_type %s in structure %s (expected cacheable or marshal_only)", s.CacheType, s.Class)
+		return
+	}
+
+	if s.CacheAs != "" && s.CacheAs != "group" {
+		reportError(s.pos.withKey("settings.cache_as"), "unknown cache_as %s in structure %s (expected group)", s.CacheAs, s.Class)
+	}
+
+	keys, ok := cacheKeys[s.CacheBy]
+	if s.CacheBy == "" {
+		reportError(s.pos.withKey("settings.cache_by"), "structure %s is cacheable but has no cache_by", s.Class)
+		return
+	} else if !ok {
+		known := make([]string, 0, len(cacheKeys))
+		for k := range cacheKeys {
+			known = append(known, k)
+		}
+		sort.Strings(known)
+		reportError(s.pos.withKey("settings.cache_by"), "unknown cache_by %s in structure %s (expected one of %s)", s.CacheBy, s.Class, strings.Join(known, "; "))
+		return
+	}
+
+	if s.IsCacheAsGroup() {
+		return
+	}
+
+	for _, key := range keys {
+		i := memberIndex(s.Members, key.name)
+		if i < 0 {
+			reportError(s.pos.withKey("settings.cache_by"), "structure %s is cached by %s but has no %s member", s.Class, s.CacheBy, key.name)
+			continue
+		}
+		m := &s.Members[i]
+		if m.IsCalc() || m.IsRemoved() {
+			reportError(m.pos, "member %s of %s is part of its cache id (cache_by = %s) and may not be calc or removed", m.Name, s.Class, s.CacheBy)
+		} else if !contains(key.types, m.Type) {
+			reportError(m.pos, "member %s of %s is part of its cache id (cache_by = %s) so its type must be %s, not %s", m.Name, s.Class, s.CacheBy, strings.Join(key.types, " or "), m.Type)
+		}
+	}
+}
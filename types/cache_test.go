@@ -0,0 +1,102 @@
+package types
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+)
+
+// TestCacheKeysMatchCacheIdStr makes sure the members checkCache requires are the fields
+// the generated cache id uses.
+func TestCacheKeysMatchCacheIdStr(t *testing.T) {
+	fieldRe := regexp.MustCompile(`s\.([A-Z][A-Za-z]*)`)
+	for cacheBy, keys := range cacheKeys {
+		st := Structure{Class: "Test", CacheBy: cacheBy}
+		used := map[string]bool{}
+		for _, match := range fieldRe.FindAllStringSubmatch(st.CacheIdStr(), -1) {
+			used[match[1]] = true
+		}
+		want := map[string]bool{}
+		for _, key := range keys {
+			want[FirstUpper(key.name)] = true
+		}
+		if len(used) != len(want) {
+			t.Errorf("cache_by %s: CacheIdStr uses %v, cacheKeys has %v", cacheBy, used, want)
+			continue
+		}
+		for field := range used {
+			if !want[field] {
+				t.Errorf("cache_by %s: CacheIdStr uses %s, which is not in cacheKeys", cacheBy, field)
+			}
+		}
+	}
+}
+
+func TestCheckCache(t *testing.T) {
+	members := func(list ...Member) []Member { return list }
+	tests := []struct {
+		name string
+		st   Structure
+		want []string
+	}{
+		{
+			name: "valid",
+			st: Structure{Class: "State", CacheType: "cacheable", CacheBy: "address,block", Members: members(
+				Member{Name: "address", Type: "address"},
+				Member{Name: "blockNumber", Type: "blknum"},
+			)},
+		},
+		{
+			name: "group",
+			st:   Structure{Class: "Slurp", CacheType: "cacheable", CacheBy: "address,tx", CacheAs: "group"},
+		},
+		{
+			name: "members",
+			st: Structure{Class: "Token", CacheType: "cacheable", CacheBy: "address,address,block", Members: members(
+				Member{Name: "holder", Type: "string"},
+				Member{Name: "address", Type: "address", Attributes: "calc"},
+			)},
+			want: []string{
+				"member address of Token is part of its cache id (cache_by = address,address,block) and may not be calc or removed",
+				"member holder of Token is part of its cache id (cache_by = address,address,block) so its type must be address, not string",
+				"structure Token is cached by address,address,block but has no blockNumber member",
+			},
+		},
+		{
+			name: "combinations",
+			st:   Structure{Class: "Function", CacheType: "marshal_only", CacheBy: "block", CacheAs: "group"},
+			want: []string{
+				"structure Function has a cache_as but its cache_type is not cacheable",
+				"structure Function has a cache_by but its cache_type is not cacheable",
+			},
+		},
+		{
+			name: "unknown",
+			st:   Structure{Class: "Block", CacheType: "cacheable", CacheBy: "blocks"},
+			want: []string{
+				"unknown cache_by blocks in structure Block (expected one of address; address,address,address,block; address,address,block; address,block; address,fourbyte,block; address,tx; block; filename; statement; tx)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetDiagnostics()
+			defer ResetDiagnostics()
+			tt.st.checkCache()
+			got := []string{}
+			for _, d := range Diagnostics() {
+				got = append(got, d.Message)
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %q, want %q", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"go/format"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/colors"
 	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
@@ -23,64 +26,77 @@ func WriteCode(existingFn, newCode string) (bool, error) {
 		return false, nil
 	}
 
-	// For new files or files in /generated/, just write the new code directly
-	if !file.FileExists(existingFn) || strings.Contains(existingFn, "/generated/") {
-		if !strings.Contains(existingFn, "/generated/") {
-			if !file.FolderExists(filepath.Dir(existingFn)) {
-				_ = file.EstablishFolder(filepath.Dir(existingFn))
-			}
-			if !verbose {
-				logger.Info(colors.Yellow+"Creating", existingFn, strings.Repeat(" ", 20)+colors.Off)
-			} else {
-				VerboseLog("  Creating new file:", existingFn)
-			}
+	isNew := !file.FileExists(existingFn)
+	isGenerated := strings.Contains(existingFn, "/generated/")
+	if isNew && !isGenerated {
+		if !verbose {
+			queueLog(LogMessage{MessageType: "Info", Message: "Creating " + existingFn, path: existingFn})
+		} else {
+			VerboseLog("  Creating new file:", existingFn)
 		}
-		return updateFile(existingFn, newCode)
+	} else if !isNew && !isGenerated {
+		VerboseLog("  Updating existing file:", existingFn)
 	}
 
-	VerboseLog("  Updating existing file:", existingFn)
-
-	tempFn := existingFn + ".new"
-	defer func() {
-		// delete the temp file when we're done
-		os.Remove(tempFn)
-	}()
-
-	// save the new code in a temporary file
-	_ = file.StringToAsciiFile(tempFn, newCode)
-
-	// extract the EXISTING_CODE from the existing file
-	existingParts, err := extractExistingCode(existingFn)
+	codeToWrite, err := renderCode(existingFn, newCode)
 	if err != nil {
-		return false, fmt.Errorf("error extracting existing code: %v", err)
+		return false, err
 	}
 
-	// apply the EXISTING_CODE to the new code
-	wasModified, err := applyTemplate(tempFn, existingParts)
-	if err != nil {
-		// If there's an error applying the template and this is a generated file,
-		// fall back to just writing the new code
-		if strings.Contains(existingFn, "/generated/") {
-			VerboseLog("  Falling back to direct write for generated file")
-			return updateFile(existingFn, newCode)
+	existingCode := file.AsciiFileToString(existingFn)
+	wasModified := codeToWrite != existingCode
+	if dryRun {
+		recordChange(existingFn, existingCode, codeToWrite, isNew)
+	} else if wasModified {
+		if !file.FolderExists(filepath.Dir(existingFn)) {
+			_ = file.EstablishFolder(filepath.Dir(existingFn))
 		}
-		return false, fmt.Errorf("error applying template: %v %s", err, existingFn)
+		_ = file.StringToAsciiFile(existingFn, codeToWrite)
+	}
+
+	// New files and files in /generated/ have already been reported (or need not be)
+	if isNew || isGenerated {
+		return wasModified, nil
 	}
 
 	existingFn = strings.Replace(existingFn, "/Users/jrush/Development", "./", 1)
 	msg := LogMessage{
 		MessageType: "Progress",
 		Message:     existingFn,
+		path:        existingFn,
 	}
-	if !strings.Contains(existingFn, "/generated/") && wasModified {
+	if wasModified && !dryRun {
 		msg.MessageType = "Info"
 		msg.Message = fmt.Sprintf("Wrote %s", existingFn)
 	}
-	logChannel <- msg
+	queueLog(msg)
 
 	return wasModified, nil
 }
 
+// renderCode returns the contents existingFn would have after generation. For existing files
+// outside of /generated/, the EXISTING_CODE sections of the current file are carried forward.
+// Nothing is written to disk.
+func renderCode(existingFn, newCode string) (string, error) {
+	if !file.FileExists(existingFn) || strings.Contains(existingFn, "/generated/") {
+		return formatCode(existingFn, newCode)
+	}
+
+	// extract the EXISTING_CODE from the existing file
+	existingParts, err := extractExistingCode(existingFn)
+	if err != nil {
+		return "", fmt.Errorf("error extracting existing code: %v", err)
+	}
+
+	// apply the EXISTING_CODE to the new code
+	merged, err := applyExistingCode(newCode, existingParts)
+	if err != nil {
+		return "", fmt.Errorf("error applying template: %v %s", err, existingFn)
+	}
+
+	return formatCode(existingFn, merged)
+}
+
 func extractExistingCode(fileName string) (map[int]string, error) {
 	file, err := os.Open(fileName)
 	if err != nil {
@@ -116,24 +132,15 @@ func extractExistingCode(fileName string) (map[int]string, error) {
 	return existingCode, nil
 }
 
-func applyTemplate(tempFn string, existingCode map[int]string) (bool, error) {
-	defer os.Remove(tempFn) // we always try to remove this file
-
-	ff, err := os.Open(tempFn)
-	if err != nil {
-		return false, err
-	}
-	defer ff.Close()
-
+func applyExistingCode(newCode string, existingCode map[int]string) (string, error) {
 	isOpen := false
-	lineCnt := 0
 	codeSection := 0
 	var buffer bytes.Buffer
-	scanner := bufio.NewScanner(ff)
+	scanner := bufio.NewScanner(strings.NewReader(newCode))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(newCode)+1)
 
 	for scanner.Scan() {
 		line := scanner.Text()
-		lineCnt++
 		if strings.Contains(line, "// EXISTING_CODE") {
 			if isOpen {
 				isOpen = false
@@ -154,13 +161,15 @@ func applyTemplate(tempFn string, existingCode map[int]string) (bool, error) {
 	}
 
 	if err := scanner.Err(); err != nil {
-		return false, err
+		return "", err
 	}
 
-	return updateFile(tempFn, buffer.String())
+	return buffer.String(), nil
 }
 
-func updateFile(tempFn, newCode string) (bool, error) {
+// formatCode removes marked lines from the code and formats it based on the extension
+// of the destination file (gofmt for Go, prettier for some others).
+func formatCode(destFn, newCode string) (string, error) {
 	lines := []string{}
 	for _, line := range strings.Split(newCode, "\n") {
 		if !strings.Contains(line, "//-- remove line --") {
@@ -168,131 +177,146 @@ func updateFile(tempFn, newCode string) (bool, error) {
 		}
 	}
 	codeToWrite := strings.Join(lines, "\n")
-	fileExt := strings.TrimPrefix(filepath.Ext(strings.TrimSuffix(tempFn, ".new")), ".")
-
-	origFn := strings.Replace(tempFn, ".new", "", 1)
-	outFn := tempFn + ".output"
-	errFn := tempFn + ".error"
-	tmpSrcFn := tempFn + ".src"
-	defer func() {
-		os.Remove(outFn)
-		os.Remove(errFn)
-		os.Remove(tmpSrcFn)
-	}()
+	fileExt := strings.TrimPrefix(filepath.Ext(destFn), ".")
 
 	if fileExt == "go" {
+		if !isGofmtEnabled() {
+			return codeToWrite, nil
+		}
 		formattedBytes, err := format.Source([]byte(codeToWrite))
 		if err != nil {
-			_, _ = showErroredCode(origFn, codeToWrite, err)
+			_, _ = showErroredCode(destFn, codeToWrite, err)
 		}
-		codeToWrite = string(formattedBytes)
+		return string(formattedBytes), nil
+	}
+
+	var parser string
+	switch fileExt {
+	case "md":
+		// parser = "markdown"
+	case "yaml", "yml":
+		parser = "yaml"
+	case "js":
+		// parser = "babel"
+	case "jsx":
+		parser = "babel"
+	case "ts":
+		// parser = "typescript"
+	case "tsx":
+		parser = "typescript"
+	default:
+		// do nothing
+	}
+	if parser == "" || !isPrettierEnabled() || !hasPrettier() {
+		return codeToWrite, nil
+	}
+
+	// prettier only works on files, so we use a scratch folder outside of the source tree
+	tmpDir, err := os.MkdirTemp("", "goMaker")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+	base := filepath.Join(tmpDir, filepath.Base(destFn))
+	tmpSrcFn := base + ".src"
+	outFn := base + ".output"
+	errFn := base + ".error"
+
+	_ = file.StringToAsciiFile(tmpSrcFn, codeToWrite)
+	prettierPath := getPrettierPath()
+
+	var cmd string
+
+	// A configuration file named in gomaker.toml is used as is
+	if _, configPath := getPrettierSettings(); configPath != "" {
+		cmd = fmt.Sprintf("%s --config %s --parser %s %s > %s 2> %s", prettierPath, configPath, parser, tmpSrcFn, outFn, errFn)
+	} else if strings.HasPrefix(prettierPath, "./frontend/") {
+		// If prettier is in frontend directory, cd there and run it
+		cmd = fmt.Sprintf("cd frontend && %s --parser %s %s > %s 2> %s",
+			strings.Replace(prettierPath, "./frontend/", "./", 1),
+			parser, tmpSrcFn, outFn, errFn)
 	} else {
-		var parser string
-		switch fileExt {
-		case "md":
-			// parser = "markdown"
-		case "yaml", "yml":
-			parser = "yaml"
-		case "js":
-			// parser = "babel"
-		case "jsx":
-			parser = "babel"
-		case "ts":
-			// parser = "typescript"
-		case "tsx":
-			parser = "typescript"
-		default:
-			// do nothing
+		// Check if there's a prettier config file that might already specify plugins
+		prettierConfigPaths := []string{
+			".prettierrc",
+			".prettierrc.json",
+			".prettierrc.js",
+			".prettierrc.yaml",
+			".prettierrc.yml",
+			"prettier.config.js",
+			"./frontend/.prettierrc",
+			"./frontend/.prettierrc.json",
+			"./frontend/.prettierrc.js",
+			"./frontend/.prettierrc.yaml",
+			"./frontend/.prettierrc.yml",
+			"./frontend/prettier.config.js",
 		}
-		if parser != "" {
-			if hasPrettier() {
-				_ = file.StringToAsciiFile(tmpSrcFn, codeToWrite)
-				prettierPath := getPrettierPath()
-
-				var cmd string
-
-				// If prettier is in frontend directory, cd there and run it
-				if strings.Contains(prettierPath, "frontend") {
-					// Get absolute paths for files since we're changing directory
-					absTmpSrcFn, _ := filepath.Abs(tmpSrcFn)
-					absOutFn, _ := filepath.Abs(outFn)
-					absErrFn, _ := filepath.Abs(errFn)
-
-					// Change to frontend directory and run prettier
-					cmd = fmt.Sprintf("cd frontend && %s --parser %s %s > %s 2> %s",
-						strings.Replace(prettierPath, "./frontend/", "./", 1),
-						parser, absTmpSrcFn, absOutFn, absErrFn)
-				} else {
-					// Check if there's a prettier config file that might already specify plugins
-					prettierConfigPaths := []string{
-						".prettierrc",
-						".prettierrc.json",
-						".prettierrc.js",
-						".prettierrc.yaml",
-						".prettierrc.yml",
-						"prettier.config.js",
-						"./frontend/.prettierrc",
-						"./frontend/.prettierrc.json",
-						"./frontend/.prettierrc.js",
-						"./frontend/.prettierrc.yaml",
-						"./frontend/.prettierrc.yml",
-						"./frontend/prettier.config.js",
-					}
-
-					hasConfig := false
-					configPath := ""
-					for _, path := range prettierConfigPaths {
-						if file.FileExists(path) {
-							hasConfig = true
-							configPath = path
-							break
-						}
-					}
-
-					if !hasConfig {
-						// Only add plugin explicitly if no config file is found
-						pluginPath := getPluginPath()
-						if pluginPath != "" {
-							cmd = fmt.Sprintf("%s --plugin %s --parser %s %s > %s 2> %s", prettierPath, pluginPath, parser, tmpSrcFn, outFn, errFn)
-						} else {
-							cmd = fmt.Sprintf("%s --parser %s %s > %s 2> %s", prettierPath, parser, tmpSrcFn, outFn, errFn)
-						}
-					} else {
-						// Use prettier config file and specify its path explicitly
-						cmd = fmt.Sprintf("%s --config %s --parser %s %s > %s 2> %s", prettierPath, configPath, parser, tmpSrcFn, outFn, errFn)
-					}
-				}
 
-				utils.System(cmd)
-				errors := file.AsciiFileToString(errFn)
-				if len(errors) > 0 {
-					return showErroredCode(tmpSrcFn, codeToWrite, fmt.Errorf("prettier errors: %s", errors))
-				}
-				codeToWrite = file.AsciiFileToString(outFn)
+		hasConfig := false
+		configPath := ""
+		for _, path := range prettierConfigPaths {
+			if file.FileExists(path) {
+				hasConfig = true
+				configPath = path
+				break
+			}
+		}
+
+		if !hasConfig {
+			// Only add plugin explicitly if no config file is found
+			pluginPath := getPluginPath()
+			if pluginPath != "" {
+				cmd = fmt.Sprintf("%s --plugin %s --parser %s %s > %s 2> %s", prettierPath, pluginPath, parser, tmpSrcFn, outFn, errFn)
+			} else {
+				cmd = fmt.Sprintf("%s --parser %s %s > %s 2> %s", prettierPath, parser, tmpSrcFn, outFn, errFn)
 			}
+		} else {
+			// Use prettier config file and specify its path explicitly
+			cmd = fmt.Sprintf("%s --config %s --parser %s %s > %s 2> %s", prettierPath, configPath, parser, tmpSrcFn, outFn, errFn)
 		}
 	}
 
-	// Compare the new formatted code to the existing file and only write if different
-	if string(codeToWrite) == file.AsciiFileToString(origFn) {
-		return false, nil
-	} else {
-		_ = file.StringToAsciiFile(origFn, string(codeToWrite)) // modified code is in the original file
-		return true, nil
+	utils.System(cmd)
+	errors := file.AsciiFileToString(errFn)
+	if len(errors) > 0 {
+		_, err := showErroredCode(destFn, codeToWrite, fmt.Errorf("prettier errors: %s", errors))
+		return "", err
 	}
+	return file.AsciiFileToString(outFn), nil
 }
 
 type LogMessage struct {
 	MessageType string
 	Message     string
+	path        string
+}
+
+var (
+	pendingLogs []LogMessage
+	logsMutex   sync.Mutex
+)
+
+// queueLog holds a message until the next call to flushLogs. Files are written in parallel,
+// so we hold the messages in order to report them in a deterministic order.
+func queueLog(msg LogMessage) {
+	logsMutex.Lock()
+	defer logsMutex.Unlock()
+	pendingLogs = append(pendingLogs, msg)
 }
 
-var logChannel = make(chan LogMessage, 100) // Buffered channel
+// flushLogs reports the queued messages sorted by the path of the file they refer to.
+func flushLogs() {
+	logsMutex.Lock()
+	msgs := pendingLogs
+	pendingLogs = nil
+	logsMutex.Unlock()
+
+	sort.SliceStable(msgs, func(i, j int) bool {
+		return msgs[i].path < msgs[j].path
+	})
 
-// syncLogger goroutine
-func syncLogger() {
 	rep := strings.Repeat(" ", 30)
-	for logMsg := range logChannel {
+	for _, logMsg := range msgs {
 		switch logMsg.MessageType {
 		case "Progress":
 			logger.Progress(true, colors.Green+logMsg.Message+colors.Off+rep)
@@ -304,15 +328,32 @@ func syncLogger() {
 	}
 }
 
-func init() {
-	go syncLogger()
-}
-
 func hasPrettier() bool {
 	return getPrettierPath() != ""
 }
 
+var (
+	prettierPath     string
+	prettierPathOnce sync.Once
+)
+
 func getPrettierPath() string {
+	prettierPathOnce.Do(func() {
+		if configured, _ := getPrettierSettings(); configured != "" {
+			prettierPath = configured
+			return
+		}
+		prettierPath = findPrettier()
+	})
+	return prettierPath
+}
+
+func resetPrettierPath() {
+	prettierPathOnce = sync.Once{}
+	prettierPath = ""
+}
+
+func findPrettier() string {
 	// Search for prettier in common locations
 	searchPaths := []string{
 		"./node_modules/.bin/prettier",                // Local install in current directory
@@ -328,13 +369,8 @@ func getPrettierPath() string {
 	}
 
 	// Fall back to global prettier if available
-	utils.System("which prettier >./found 2>/dev/null")
-	defer os.Remove("./found")
-	if file.FileExists("./found") {
-		contents := file.AsciiFileToString("./found")
-		if len(contents) > 0 {
-			return "prettier"
-		}
+	if _, err := exec.LookPath("prettier"); err == nil {
+		return "prettier"
 	}
 
 	return ""
@@ -0,0 +1,210 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// These settings let a program that embeds the generator configure it without touching
+// the environment. An empty (or false) setting falls back to the corresponding
+// environment variable and then to gomaker.toml (see project.go).
+var (
+	templatesPath   string
+	overlayPaths    []string
+	generatorsPath  string
+	singleFilter    string
+	generatorFilter string
+	remoteTesting   bool
+	outputRoot      string
+)
+
+// SetTemplatesPath sets the templates folder (overrides TB_TEMPLATES_PATH).
+func SetTemplatesPath(path string) {
+	templatesPath = path
+	resetTemplatePath()
+}
+
+// SetTemplateOverlays sets the folders layered, in order, over the templates folder
+// (overrides TB_TEMPLATES_OVERLAYS). See templateLayers.
+func SetTemplateOverlays(paths []string) {
+	overlayPaths = paths
+}
+
+// SetGeneratorsPath sets the generators folder (overrides TB_GENERATORS_PATH).
+func SetGeneratorsPath(path string) {
+	generatorsPath = path
+}
+
+// SetSingle limits processing to templates whose path contains the given string
+// (overrides TB_MAKER_SINGLE).
+func SetSingle(single string) {
+	singleFilter = single
+}
+
+// SetFilter limits generation to generators whose path contains the given string
+// (overrides TB_GENERATOR_FILTER).
+func SetFilter(filter string) {
+	generatorFilter = filter
+}
+
+// SetRemoteTesting turns off the check that stops generation when the codebase has
+// changed (same as TB_REMOTE_TESTING=true).
+func SetRemoteTesting(v bool) {
+	remoteTesting = v
+}
+
+// SetOutputRoot sets the folder that generated paths (and the default template search
+// paths) are relative to. By default, that is the current working directory.
+func SetOutputRoot(path string) {
+	outputRoot = path
+	resetTemplatePath()
+}
+
+func getTemplatesPathSetting() string {
+	if ret := orEnv(templatesPath, "TB_TEMPLATES_PATH"); ret != "" || project == nil {
+		return ret
+	}
+	return project.Paths.Templates
+}
+
+func getOverlaysSetting() []string {
+	if len(overlayPaths) > 0 {
+		return overlayPaths
+	}
+	if env := os.Getenv("TB_TEMPLATES_OVERLAYS"); env != "" {
+		return filepath.SplitList(env)
+	}
+	if project == nil {
+		return nil
+	}
+	return project.Paths.Overlays
+}
+
+func getGeneratorsPathSetting() string {
+	if ret := orEnv(generatorsPath, "TB_GENERATORS_PATH"); ret != "" || project == nil {
+		return ret
+	}
+	return project.Paths.Generators
+}
+
+func getSingle() string {
+	return orEnv(singleFilter, "TB_MAKER_SINGLE")
+}
+
+func getFilter() string {
+	return orEnv(generatorFilter, "TB_GENERATOR_FILTER")
+}
+
+func isRemoteTesting() bool {
+	return remoteTesting || os.Getenv("TB_REMOTE_TESTING") == "true"
+}
+
+func orEnv(value, key string) string {
+	if value != "" {
+		return value
+	}
+	return os.Getenv(key)
+}
+
+// getOutputRootSetting returns the output root if one is set (an empty string otherwise).
+func getOutputRootSetting() string {
+	if outputRoot != "" || project == nil {
+		return outputRoot
+	}
+	return project.Paths.Output
+}
+
+// getOutputRoot returns the folder generated paths are relative to.
+func getOutputRoot() string {
+	if root := getOutputRootSetting(); root != "" {
+		return root
+	}
+	cwd, _ := os.Getwd()
+	return cwd
+}
+
+// inOutputRoot returns path relative to the output root. Absolute paths, and all paths
+// when no output root is set, are returned unchanged.
+func inOutputRoot(path string) string {
+	root := getOutputRootSetting()
+	if root == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, path)
+}
+
+// getHelpFolder returns the folder holding the frontend's help files.
+func getHelpFolder() string {
+	if project != nil && project.Paths.Help != "" {
+		return project.Paths.Help
+	}
+	return filepath.Join(getOutputRoot(), "frontend/src/assets/help")
+}
+
+// getValidatorPath returns the path to the route's validate.go.
+func getValidatorPath(route string) string {
+	pattern := "chifra/internal/[[route]]/validate.go"
+	if project != nil && project.Paths.Validators != "" {
+		pattern = project.Paths.Validators
+	}
+	path := strings.ReplaceAll(pattern, "[[route]]", route)
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(getOutputRoot(), path)
+}
+
+// isCategoryEnabled returns true if the generators in the category (codebase, groups,
+// routes, or types) should run.
+func isCategoryEnabled(category string) bool {
+	if project == nil || len(project.Generators.Enabled) == 0 {
+		return true
+	}
+	return slices.Contains(project.Generators.Enabled, category)
+}
+
+// allCategoriesEnabled returns true if gomaker.toml does not turn off any generator category.
+func allCategoriesEnabled() bool {
+	for _, category := range generatorCategories {
+		if !isCategoryEnabled(category) {
+			return false
+		}
+	}
+	return true
+}
+
+func isGofmtEnabled() bool {
+	return project == nil || project.Formatter.Gofmt == nil || *project.Formatter.Gofmt
+}
+
+func isPrettierEnabled() bool {
+	return project == nil || project.Formatter.Prettier == nil || *project.Formatter.Prettier
+}
+
+// getPrettierSettings returns the prettier and the configuration file set in gomaker.toml.
+func getPrettierSettings() (string, string) {
+	if project == nil {
+		return "", ""
+	}
+	return project.Formatter.PrettierPath, project.Formatter.PrettierConfig
+}
+
+// getGeneratorPath returns the full path to the named template in the generators folder
+// of the last template layer that has it.
+func getGeneratorPath(against, source string) string {
+	path := generatorFile(filepath.Join(against, source))
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(getOutputRoot(), path)
+}
+
+func resetTemplatePath() {
+	templatesPathOnce = sync.Once{}
+	cachedTemplatesPath = ""
+	templatesPathError = nil
+	rootFolder = "dev-tools/goMaker/"
+}
@@ -0,0 +1,91 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSettingPrecedence checks that a setting (a flag in the binary) wins over the
+// environment variable, which wins over gomaker.toml.
+func TestSettingPrecedence(t *testing.T) {
+	defer func() {
+		SetTemplatesPath("")
+		SetGeneratorsPath("")
+		SetTemplateOverlays(nil)
+		SetSingle("")
+		SetFilter("")
+		SetRemoteTesting(false)
+		SetProjectConfig(nil)
+	}()
+
+	cfg := &ProjectConfig{Paths: ProjectPaths{Templates: "toml/templates", Generators: "toml/generators", Overlays: []string{"toml/overlay"}}}
+	tests := []struct {
+		name    string
+		set     func(string)
+		env     string
+		get     func() string
+		fromCfg string
+	}{
+		{"templates", SetTemplatesPath, "TB_TEMPLATES_PATH", getTemplatesPathSetting, "toml/templates"},
+		{"generators", SetGeneratorsPath, "TB_GENERATORS_PATH", getGeneratorsPathSetting, "toml/generators"},
+		{"single", SetSingle, "TB_MAKER_SINGLE", getSingle, ""},
+		{"filter", SetFilter, "TB_GENERATOR_FILTER", getFilter, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, c := range []struct {
+				setting, env string
+				cfg          *ProjectConfig
+				want         string
+			}{
+				{"", "", nil, ""},
+				{"", "", cfg, tt.fromCfg},
+				{"", "from-env", cfg, "from-env"},
+				{"from-flag", "from-env", cfg, "from-flag"},
+				{"from-flag", "", nil, "from-flag"},
+			} {
+				tt.set(c.setting)
+				t.Setenv(tt.env, c.env)
+				SetProjectConfig(c.cfg)
+				if got := tt.get(); got != c.want {
+					t.Errorf("setting %q, env %q, config %v: got %q, want %q", c.setting, c.env, c.cfg != nil, got, c.want)
+				}
+			}
+		})
+	}
+
+	t.Run("overlays", func(t *testing.T) {
+		SetProjectConfig(cfg)
+		t.Setenv("TB_TEMPLATES_OVERLAYS", "")
+		SetTemplateOverlays(nil)
+		if got := getOverlaysSetting(); !reflect.DeepEqual(got, []string{"toml/overlay"}) {
+			t.Errorf("expected the configuration's overlays, got %v", got)
+		}
+		t.Setenv("TB_TEMPLATES_OVERLAYS", "env/a:env/b")
+		if got := getOverlaysSetting(); !reflect.DeepEqual(got, []string{"env/a", "env/b"}) {
+			t.Errorf("expected the environment's overlays, got %v", got)
+		}
+		SetTemplateOverlays([]string{"flag"})
+		if got := getOverlaysSetting(); !reflect.DeepEqual(got, []string{"flag"}) {
+			t.Errorf("expected the flag's overlays, got %v", got)
+		}
+	})
+
+	t.Run("remote testing", func(t *testing.T) {
+		SetRemoteTesting(false)
+		t.Setenv("TB_REMOTE_TESTING", "")
+		if isRemoteTesting() {
+			t.Error("expected remote testing to be off")
+		}
+		t.Setenv("TB_REMOTE_TESTING", "true")
+		if !isRemoteTesting() {
+			t.Error("expected TB_REMOTE_TESTING=true to turn on remote testing")
+		}
+		t.Setenv("TB_REMOTE_TESTING", "")
+		SetRemoteTesting(true)
+		if !isRemoteTesting() {
+			t.Error("expected the setting to turn on remote testing")
+		}
+	})
+}
@@ -1,69 +1,194 @@
 package types
 
 import (
+	"bytes"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"reflect"
+	"strconv"
 	"strings"
-
-	"github.com/gocarina/gocsv"
-
-	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
 )
 
 type Validater interface {
 	Validate() bool
 }
 
+// positioner is implemented by records that remember where they were defined.
+type positioner interface {
+	setPos(pos Position)
+}
+
+// columnRequirer is implemented by records whose files must have certain columns.
+type columnRequirer interface {
+	requiredColumns() []string
+}
+
 // LoadCsv loads a csv file into a Validater (which is any type that implements the Validate() method).
 // The callBack function is called for each record in the csv file. If the callBack function returns false,
 // the record is skipped. If the callBack function returns an error, the function quits and returns the error.
+//
+// Lines starting with # and blank lines are ignored. The file is read in memory, so positions (in errors
+// and diagnostics) are lines in the file itself. Values that cannot be converted to their field's type are
+// reported as errors (with their line and column) and left empty.
 func LoadCsv[T Validater, D any](basePath string, callBack func(*T, *D) (bool, error), data *D) ([]T, error) {
-	lines := file.AsciiFileToLines(basePath)
-
-	isCommandFile := strings.Contains(basePath, "cmd-line-options.csv")
-	isBaseTypes := strings.Contains(basePath, "base-types.csv")
-	if !isCommandFile && !isBaseTypes {
-		requiredColumns := []string{"name", "type", "docOrder", "description"}
-		joinedLines := strings.Join(lines, "\n")
-		for _, col := range requiredColumns {
-			if !strings.Contains(joinedLines, col) {
-				return nil, fmt.Errorf("no %s column found in %s", col, basePath)
-			}
-		}
+	contents, err := os.ReadFile(basePath)
+	if err != nil {
+		return []T{}, err
 	}
 
-	noComments := make([]string, 0, len(lines))
-	for i := 0; i < len(lines); i++ {
-		if !strings.HasPrefix(lines[i], "#") && len(strings.Trim(lines[i], wss)) > 0 {
-			noComments = append(noComments, lines[i])
+	// Blank lines holding only whitespace (rather than removing them) to keep line numbers
+	lines := strings.Split(string(contents), "\n")
+	for i := range lines {
+		if len(strings.Trim(lines[i], wss)) == 0 {
+			lines[i] = ""
 		}
 	}
-	basePath += ".tmp"
-	_ = file.StringToAsciiFile(basePath, strings.Join(noComments, "\n"))
-	defer os.Remove(basePath)
+
+	reader := csv.NewReader(bytes.NewReader([]byte(strings.Join(lines, "\n"))))
+	reader.Comment = '#'
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%s: no header found", basePath)
+	} else if err != nil {
+		return nil, csvError(basePath, err)
+	}
+	headerLine, _ := reader.FieldPos(0)
+
+	columns, err := csvColumns[T](header)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%d: %w", basePath, headerLine, err)
+	}
 
 	records := make([]T, 0)
-	callbackFunc := func(record T) error {
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return []T{}, csvError(basePath, err)
+		}
+
+		var record T
+		value := reflect.ValueOf(&record).Elem()
+		line, _ := reader.FieldPos(0)
+		for i, field := range fields {
+			if columns[i] == nil {
+				continue
+			}
+			if err := setCsvField(value.FieldByIndex(columns[i].Index), field); err != nil {
+				_, col := reader.FieldPos(i)
+				reportError(Position{File: basePath, Line: line, Column: col, Key: header[i]}, "%v", err)
+			}
+		}
+		if p, ok := any(&record).(positioner); ok {
+			p.setPos(Position{File: basePath, Line: line})
+		}
+
 		ok, err := callBack(&record, data)
 		if err != nil {
-			return err
+			return []T{}, err
 		} else if !ok {
-			return nil
+			continue
 		}
 		if record.Validate() {
 			records = append(records, record)
 		}
-		return nil
 	}
 
-	if theFile, err := os.OpenFile(basePath, os.O_RDWR, os.ModePerm); err != nil {
-		return []T{}, err
-	} else {
-		defer theFile.Close()
-		if err := gocsv.UnmarshalToCallback(theFile, callbackFunc); err != nil {
-			return []T{}, err
+	return records, nil
+}
+
+// csvColumns returns, for each column in the header, the field of T it is read into. Header
+// names are trimmed and must match a field's csv tag exactly. Columns T requires must be present.
+func csvColumns[T any](header []string) ([]*reflect.StructField, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	fields := map[string]*reflect.StructField{}
+	known := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if name, _, _ := strings.Cut(f.Tag.Get("csv"), ","); name != "" && name != "-" {
+			fields[name] = &f
+			known = append(known, name)
 		}
 	}
 
-	return records, nil
+	ret := make([]*reflect.StructField, len(header))
+	seen := map[string]bool{}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+		name := header[i]
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate column %s", name)
+		}
+		seen[name] = true
+		if ret[i] = fields[name]; ret[i] == nil {
+			if suggestion := nearestKey(name, known); suggestion != "" {
+				return nil, fmt.Errorf("unknown column %s (did you mean %s?)", name, suggestion)
+			}
+			return nil, fmt.Errorf("unknown column %s", name)
+		}
+	}
+
+	if r, ok := any(new(T)).(columnRequirer); ok {
+		for _, col := range r.requiredColumns() {
+			if !seen[col] {
+				return nil, fmt.Errorf("no %s column found", col)
+			}
+		}
+	}
+	return ret, nil
+}
+
+// setCsvField converts the value to the field's type. Numbers may be surrounded by spaces
+// and are zero if empty.
+func setCsvField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return nil
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		field.SetInt(n)
+	case reflect.Float64, reflect.Float32:
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return nil
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("cannot read a %s from a csv file", field.Type())
+	}
+	return nil
+}
+
+// csvError adds the file's path to errors from the csv reader (whose lines are the file's lines).
+func csvError(path string, err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("%s:%d:%d: %w", path, parseErr.Line, parseErr.Column, parseErr.Err)
+	}
+	return fmt.Errorf("%s: %w", path, err)
 }
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadCsvPositions(t *testing.T) {
+	ResetDiagnostics()
+	defer ResetDiagnostics()
+
+	path := filepath.Join(t.TempDir(), "block.csv")
+	contents := `# a comment
+name ,type   ,strDefault ,attributes ,docOrder ,description
+
+hash ,hash   ,           ,           ,1        ,the hash
+# another comment
+   
+number ,blknum ,         ,           ,two      ,the number
+`
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	members, err := LoadCsv(path, readMember, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 2 || members[0].pos.Line != 4 || members[1].pos.Line != 7 {
+		t.Fatalf("expected members on lines 4 and 7, got %+v", members)
+	}
+	if members[0].DocOrder != 1 {
+		t.Errorf("expected docOrder 1, got %d", members[0].DocOrder)
+	}
+
+	diags := Diagnostics()
+	if len(diags) != 1 {
+		t.Fatalf("expected one diagnostic, got %v", diags)
+	}
+	d := diags[0]
+	if d.Line != 7 || d.Column != 39 || d.Key != "docOrder" || d.Message != `"two" is not an integer` {
+		t.Errorf("unexpected diagnostic %+v", d)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("a temporary file was left behind")
+	}
+}
+
+func TestLoadCsvColumns(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"name,type,docOrdr,description", "unknown column docOrdr (did you mean docOrder?)"},
+		{"name,type,description", "no docOrder column found"},
+		{"name,type,type,docOrder,description", "duplicate column type"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "block.csv")
+		if err := os.WriteFile(path, []byte("# comment\n"+tt.header+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadCsv(path, readMember, nil)
+		if err == nil || !strings.HasSuffix(err.Error(), ":2: "+tt.want) {
+			t.Errorf("%s: expected %q, got %v", tt.header, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
+)
+
+// Dependency records what a single generated file was produced from: the hash of each
+// input file (the generator template, partials, intro and notes markdown), the hash of the
+// receiver the template was applied to, and the hash of the file that was written.
+type Dependency struct {
+	Template     string            `json:"template"`
+	Receiver     string            `json:"receiver"`
+	ReceiverHash string            `json:"receiverHash"`
+	Inputs       map[string]string `json:"inputs"`
+	Output       string            `json:"output"`
+}
+
+// DependencyGraph maps each generated file to its dependencies. It is stored in the
+// generated folder between runs.
+type DependencyGraph struct {
+	Version string                `json:"version"`
+	Outputs map[string]Dependency `json:"outputs"`
+}
+
+var (
+	incremental bool = false
+	toolVersion      = "unknown"
+	graph            = DependencyGraph{Outputs: map[string]Dependency{}}
+	graphMutex  sync.Mutex
+	hashCache   = map[string]string{}
+	hashMutex   sync.Mutex
+	skipped     int
+	fingerprint string
+	formatting  string
+)
+
+// SetIncremental turns on (or off) incremental generation. When on, outputs whose inputs
+// have not changed since the last run are not regenerated.
+func SetIncremental(v bool) {
+	incremental = v
+}
+
+// SetVersion sets the version of goMaker. A change of version invalidates the dependency graph.
+func SetVersion(v string) {
+	toolVersion = v
+}
+
+func getDependencyGraphPath() string {
+	return filepath.Join(GetGeneratedPath(), "dependencies.json")
+}
+
+// loadDependencyGraph reads the graph written by the previous run (if any).
+func loadDependencyGraph() {
+	graphMutex.Lock()
+	defer graphMutex.Unlock()
+
+	hashMutex.Lock()
+	hashCache = map[string]string{}
+	hashMutex.Unlock()
+
+	skipped = 0
+	graph = DependencyGraph{Outputs: map[string]Dependency{}}
+	contents := file.AsciiFileToString(getDependencyGraphPath())
+	var previous DependencyGraph
+	if err := json.Unmarshal([]byte(contents), &previous); err != nil || previous.Version != toolVersion {
+		VerboseLog("Ignoring dependency graph:", getDependencyGraphPath())
+		return
+	}
+	if previous.Outputs != nil {
+		graph = previous
+	}
+}
+
+// saveDependencyGraph writes the graph for use by the next run.
+func saveDependencyGraph() error {
+	graphMutex.Lock()
+	defer graphMutex.Unlock()
+
+	graph.Version = toolVersion
+	bytes, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		return err
+	}
+	return file.StringToAsciiFile(getDependencyGraphPath(), string(bytes)+"\n")
+}
+
+// upToDate returns true if dest was produced by an earlier run from exactly these
+// dependencies and has not been touched since.
+func upToDate(dest string, deps Dependency) bool {
+	if !incremental || dryRun {
+		return false
+	}
+
+	graphMutex.Lock()
+	prev, ok := graph.Outputs[cleanOutputPath(dest)]
+	graphMutex.Unlock()
+	if !ok || prev.ReceiverHash != deps.ReceiverHash || len(prev.Inputs) != len(deps.Inputs) {
+		return false
+	}
+	for path, hash := range deps.Inputs {
+		if prev.Inputs[path] != hash {
+			return false
+		}
+	}
+	if prev.Output == "" || prev.Output != hashFile(dest, false) {
+		return false
+	}
+
+	graphMutex.Lock()
+	skipped++
+	graphMutex.Unlock()
+	markProduced(dest, prev)
+	return true
+}
+
+// recordDependency notes the dependencies of a file that was just written.
+func recordDependency(dest string, deps Dependency) {
+	if dryRun {
+		return
+	}
+	deps.Output = hashFile(dest, false)
+	graphMutex.Lock()
+	graph.Outputs[cleanOutputPath(dest)] = deps
+	graphMutex.Unlock()
+	markProduced(dest, deps)
+}
+
+// setFingerprint records the hash of the codebase and the formatter settings at the start
+// of generation. Every output is formatted, so every receiver's hash includes the
+// settings. Codebase and group templates may read anything, so theirs also includes the
+// hash of the whole codebase (see receiverHash).
+func setFingerprint(cb *CodeBase) {
+	formatting = formatterSettings()
+	fingerprint = hashString(cb.String() + formatting)
+}
+
+// receiverHash returns the hash of the receiver of a codebase or group template.
+func receiverHash(receiver string) string {
+	return hashString(receiver + fingerprint)
+}
+
+// commandHash returns the hash of what a route template reads: the command (including the
+// structures it produces), the structures named by its return type and by the members of
+// what it produces, and the formatter settings.
+func commandHash(c *Command) string {
+	parts := []string{asJson(c)}
+	if c.cbPtr != nil {
+		related := map[string]*Structure{}
+		if st := c.cbPtr.findStructure(Lower(c.ReturnType)); st != nil {
+			related[st.Class] = st
+		}
+		for _, production := range c.Productions {
+			for _, m := range production.Members {
+				if st := c.cbPtr.findStructure(Lower(m.Type)); st != nil {
+					related[st.Class] = st
+				}
+			}
+		}
+		parts = append(parts, structureStrings(related)...)
+	}
+	return hashString(strings.Join(parts, "\n") + formatting)
+}
+
+// structureHash returns the hash of what a type template reads: the structure, the groups
+// of the routes that produce it, the structures it names (its parent, children, contents,
+// containers, the structure it extends, its members' types, and its stores' models), and
+// the formatter settings.
+func structureHash(s *Structure) string {
+	parts := []string{s.String()}
+	if cb := s.cbPtr; cb != nil {
+		for _, route := range s.Producers {
+			parts = append(parts, route+":"+cb.RouteToGroup(route))
+		}
+
+		names := []string{s.Parent, s.Extends}
+		names = append(names, strings.Split(s.Contains, ",")...)
+		names = append(names, strings.Split(s.ContainedBy, ",")...)
+		for _, m := range s.Members {
+			names = append(names, m.Type)
+		}
+		children := map[string]bool{}
+		for _, ch := range s.ChildTabs {
+			children[ch] = true
+		}
+		stores := map[string]bool{}
+		for _, store := range s.Stores() {
+			stores[Lower(Singular(store.Name))] = true
+		}
+
+		related := map[string]*Structure{}
+		for _, name := range names {
+			if st := cb.findStructure(Lower(strings.TrimSpace(name))); st != nil && st.Class != s.Class {
+				related[st.Class] = st
+			}
+		}
+		for i := range cb.Structures {
+			st := &cb.Structures[i]
+			if st.Class != s.Class && (children[Plural(st.Name())] || stores[Lower(Singular(st.Class))]) {
+				related[st.Class] = st
+			}
+		}
+		parts = append(parts, structureStrings(related)...)
+	}
+	return hashString(strings.Join(parts, "\n") + formatting)
+}
+
+// structureStrings serializes the structures in the order of their names.
+func structureStrings(structures map[string]*Structure) []string {
+	names := make([]string, 0, len(structures))
+	for name := range structures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	ret := make([]string, 0, len(names))
+	for _, name := range names {
+		ret = append(ret, structures[name].String())
+	}
+	return ret
+}
+
+// formatterSettings describes the settings that change how generated code is formatted.
+func formatterSettings() string {
+	path, config := getPrettierSettings()
+	return fmt.Sprintf("gofmt=%t prettier=%t prettier_path=%s prettier_config=%s:%s found=%s",
+		isGofmtEnabled(), isPrettierEnabled(), path, config, hashFile(config, false), getPrettierPath())
+}
+
+// dependencies returns the inputs of the output produced by applying the template at
+// fullPath to the named receiver whose state is captured by receiverHash. Which intro
+// and notes files are read depends on the template's scope.
+func dependencies(fullPath, against, receiver, receiverHash string) Dependency {
+	deps := Dependency{
+		Template:     cleanOutputPath(fullPath),
+		Receiver:     against + ":" + receiver,
+		ReceiverHash: receiverHash,
+		Inputs:       map[string]string{},
+	}
+
+	addFile := func(path string) {
+		deps.Inputs[cleanOutputPath(path)] = hashFile(path, true)
+	}
+	addFolder := func(folder string, suffix string) {
+		entries, _ := os.ReadDir(folder)
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), suffix) {
+				addFile(filepath.Join(folder, entry.Name()))
+			}
+		}
+	}
+	// Intros and partials come from the last template layer that has them
+	addLayered := func(rel, suffix string, generators bool) {
+		for _, path := range folderFiles(rel, suffix, generators) {
+			addFile(path)
+		}
+	}
+
+	addFile(fullPath)
+	if rel, ok := generatorRel(fullPath); ok {
+		addLayered(filepath.Dir(rel), ".partial.tmpl", true)
+	} else {
+		addFolder(filepath.Dir(fullPath), ".partial.tmpl")
+	}
+
+	switch against {
+	case "routes":
+		addFile(templateFile(filepath.Join("readme-intros", receiver+".md")))
+		addFile(templateFile(filepath.Join("readme-intros", receiver+".notes.md")))
+		addFile(templateFile(filepath.Join("readme-intros", "README.footer.md")))
+	case "types":
+		addFile(templateFile(filepath.Join("model-intros", CamelCase(receiver)+".md")))
+		addFile(templateFile(filepath.Join("model-intros", CamelCase(receiver)+".notes.md")))
+	default:
+		// codebase and group templates may pull in anything
+		for _, folder := range []string{"readme-intros", "model-intros", "readme-groups", "model-groups"} {
+			addLayered(folder, ".md", false)
+		}
+		addFolder(GetGeneratedPath(), ".md")
+	}
+
+	return deps
+}
+
+// hashFile returns the hash of the file's contents (or an empty string if it does not
+// exist). Inputs are cached for the duration of the run, outputs are not.
+func hashFile(path string, cache bool) string {
+	if cache {
+		hashMutex.Lock()
+		defer hashMutex.Unlock()
+		if hash, ok := hashCache[path]; ok {
+			return hash
+		}
+	}
+
+	hash := ""
+	if file.FileExists(path) {
+		hash = hashString(file.AsciiFileToString(path))
+	}
+	if cache {
+		hashCache[path] = hash
+	}
+	return hash
+}
+
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,164 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestUpToDate(t *testing.T) {
+	cb, templates := loadTemplatesCopy(t)
+	SetDryRun(false)
+	SetIncremental(true)
+	savedGraph := graph
+	graph = DependencyGraph{Outputs: map[string]Dependency{}}
+	defer func() {
+		SetIncremental(false)
+		SetProjectConfig(nil)
+		graph = savedGraph
+		hashCache = map[string]string{}
+		fingerprint = ""
+		resetProduced()
+	}()
+
+	var blocks *Command
+	for i := range cb.Commands {
+		if cb.Commands[i].Route == "blocks" {
+			blocks = &cb.Commands[i]
+		}
+	}
+	if blocks == nil {
+		t.Fatal("no blocks command")
+	}
+
+	template := getGeneratorPath("routes", "src_dev+tools_goMaker_generated_readme+route.md.tmpl")
+	dest := filepath.Join(t.TempDir(), "blocks.md")
+	writeFile(t, dest, "generated\n")
+
+	// deps starts a new run (inputs are only hashed once per run) and returns the
+	// dependencies of the blocks readme
+	deps := func() Dependency {
+		hashCache = map[string]string{}
+		setFingerprint(&cb)
+		return dependencies(template, "routes", blocks.Route, commandHash(blocks))
+	}
+	recordDependency(dest, deps())
+	if !upToDate(dest, deps()) {
+		t.Fatal("expected the output to be up to date right after it was recorded")
+	}
+
+	// A structure the route neither produces nor names
+	var unrelated *Structure
+	for i := range cb.Structures {
+		if st := &cb.Structures[i]; !strings.Contains(asJson(blocks), `"`+st.Class+`"`) && Lower(st.Class) != blocks.ReturnType {
+			unrelated = st
+			break
+		}
+	}
+	if unrelated == nil || len(blocks.Productions) == 0 {
+		t.Fatal("expected blocks to produce a structure and not to name every structure")
+	}
+
+	gofmt := false
+	tests := []struct {
+		name   string
+		change func()
+		stale  bool
+	}{
+		{"template", func() { writeFile(t, template, "{{.Route}} changed\n") }, true},
+		{"new partial", func() {
+			writeFile(t, filepath.Join(templates, "generators", "routes", "opts.partial.tmpl"), "{{.Route}}")
+		}, true},
+		{"changed partial", func() {
+			writeFile(t, filepath.Join(templates, "generators", "routes", "opts.partial.tmpl"), "{{.Tool}}")
+		}, true},
+		{"intro", func() { writeFile(t, filepath.Join(templates, "readme-intros", "blocks.md"), "changed\n") }, true},
+		{"notes", func() { writeFile(t, filepath.Join(templates, "readme-intros", "blocks.notes.md"), "changed\n") }, true},
+		{"footer", func() { writeFile(t, filepath.Join(templates, "readme-intros", "README.footer.md"), "changed\n") }, true},
+		{"receiver", func() { blocks.Description = "changed" }, true},
+		{"a produced structure", func() { blocks.Productions[0].DocDescr = "changed" }, true},
+		{"an unrelated structure", func() { unrelated.DocDescr = "changed" }, false},
+		{"base types", func() { cb.BaseTypes[0].DocDescr = "changed" }, false},
+		{"formatter", func() { SetProjectConfig(&ProjectConfig{Formatter: FormatterConfig{Gofmt: &gofmt}}) }, true},
+		{"output edited by hand", func() { writeFile(t, dest, "edited\n") }, true},
+	}
+	for _, tt := range tests {
+		tt.change()
+		if upToDate(dest, deps()) == tt.stale {
+			t.Errorf("%s: expected the output to be up to date %t", tt.name, !tt.stale)
+		}
+		// Regenerating brings it up to date again
+		recordDependency(dest, deps())
+		if !upToDate(dest, deps()) {
+			t.Errorf("%s: expected the output to be up to date after it was recorded", tt.name)
+		}
+	}
+
+	SetIncremental(false)
+	if upToDate(dest, deps()) {
+		t.Error("nothing is up to date unless generation is incremental")
+	}
+	SetIncremental(true)
+	SetDryRun(true)
+	if upToDate(dest, deps()) {
+		t.Error("nothing is up to date in a dry run")
+	}
+	SetDryRun(false)
+
+	if err := os.Remove(dest); err != nil {
+		t.Fatal(err)
+	}
+	if upToDate(dest, deps()) {
+		t.Error("a missing output is not up to date")
+	}
+}
+
+func TestFieldsChangeRegeneratesItsType(t *testing.T) {
+	_, root := generateCopy(t)
+	SetIncremental(true)
+	defer SetIncremental(false)
+
+	receiverHashes := func() map[string]string {
+		ret := map[string]string{}
+		for _, dep := range graph.Outputs {
+			ret[dep.Receiver] = dep.ReceiverHash
+		}
+		return ret
+	}
+	before := receiverHashes()
+
+	fields := filepath.Join(root, "templates", "classDefinitions", "fields", "bounds.csv")
+	contents, err := os.ReadFile(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fields, strings.Replace(string(contents), "the first appearance", "the very first appearance", 1))
+	// The edit changes codebase.json
+	t.Setenv("TB_REMOTE_TESTING", "true")
+	cb, err := LoadCodebase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cb.GenerateOnly(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Bounds, the route that produces it, and the structure it contains are regenerated.
+	// Codebase and group templates may read anything, so they are too.
+	regenerated := []string{}
+	for receiver, hash := range receiverHashes() {
+		if before[receiver] != hash && (strings.HasPrefix(receiver, "routes:") || strings.HasPrefix(receiver, "types:")) {
+			regenerated = append(regenerated, receiver)
+		}
+	}
+	sort.Strings(regenerated)
+	if want := []string{"routes:list", "types:Appearance", "types:Bounds"}; !reflect.DeepEqual(regenerated, want) {
+		t.Errorf("got %v regenerated, want %v", regenerated, want)
+	}
+	if skipped == 0 {
+		t.Error("expected the outputs of other routes and types to be skipped")
+	}
+}
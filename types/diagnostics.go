@@ -0,0 +1,144 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Severity is the seriousness of a Diagnostic.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Position locates a problem in the model's source files. Line is the line in a CSV file
+// (counting from one, including the header and comments) and Column the column on that line
+// (if known). Key is the key in a TOML file or the column's name in a CSV file.
+type Position struct {
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+	Key    string `json:"key,omitempty"`
+}
+
+func (p Position) String() string {
+	ret := cleanOutputPath(p.File)
+	if p.File == "" {
+		ret = "<unknown>"
+	}
+	if p.Line > 0 {
+		ret += ":" + strconv.Itoa(p.Line)
+		if p.Column > 0 {
+			ret += ":" + strconv.Itoa(p.Column)
+		}
+	}
+	if p.Key != "" {
+		ret += " [" + p.Key + "]"
+	}
+	return ret
+}
+
+// withKey returns the position of the given key in the same file.
+func (p Position) withKey(key string) Position {
+	p.Key = key
+	return p
+}
+
+// Diagnostic is a single error or warning found while loading the codebase.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Position
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return d.Position.String() + ": " + string(d.Severity) + ": " + d.Message
+}
+
+var (
+	diagnostics      []Diagnostic
+	diagnosticsMutex sync.Mutex
+	warningsAsErrors bool = false
+)
+
+// SetWarningsAsErrors makes loading fail if there are warnings, not only errors.
+func SetWarningsAsErrors(v bool) {
+	warningsAsErrors = v
+}
+
+// ResetDiagnostics forgets the diagnostics reported so far.
+func ResetDiagnostics() {
+	diagnosticsMutex.Lock()
+	defer diagnosticsMutex.Unlock()
+	diagnostics = nil
+}
+
+// Diagnostics returns the errors and warnings found during the last load sorted by file
+// and line.
+func Diagnostics() []Diagnostic {
+	diagnosticsMutex.Lock()
+	defer diagnosticsMutex.Unlock()
+	ret := append([]Diagnostic{}, diagnostics...)
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].File != ret[j].File {
+			return ret[i].File < ret[j].File
+		}
+		return ret[i].Line < ret[j].Line
+	})
+	return ret
+}
+
+func reportError(pos Position, format string, args ...any) {
+	report(SeverityError, pos, format, args...)
+}
+
+func reportWarning(pos Position, format string, args ...any) {
+	report(SeverityWarning, pos, format, args...)
+}
+
+func report(severity Severity, pos Position, format string, args ...any) {
+	diagnosticsMutex.Lock()
+	defer diagnosticsMutex.Unlock()
+	diagnostics = append(diagnostics, Diagnostic{
+		Severity: severity,
+		Position: pos,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// diagnosticsSince returns the diagnostics reported after the first n, in the order they
+// were reported, and the number reported so far.
+func diagnosticsSince(n int) ([]Diagnostic, int) {
+	diagnosticsMutex.Lock()
+	defer diagnosticsMutex.Unlock()
+	if n > len(diagnostics) {
+		n = len(diagnostics)
+	}
+	return append([]Diagnostic{}, diagnostics[n:]...), len(diagnostics)
+}
+
+// diagnosticsError returns an error if any errors (or, with warnings-as-errors, any
+// warnings) have been reported.
+func diagnosticsError() error {
+	diagnosticsMutex.Lock()
+	defer diagnosticsMutex.Unlock()
+	nErrors, nWarnings := 0, 0
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			nErrors++
+		} else {
+			nWarnings++
+		}
+	}
+	if nErrors > 0 {
+		return fmt.Errorf("found %d error(s) and %d warning(s) in the model", nErrors, nWarnings)
+	}
+	if warningsAsErrors && nWarnings > 0 {
+		return fmt.Errorf("found %d warning(s) in the model (warnings are treated as errors)", nWarnings)
+	}
+	return nil
+}
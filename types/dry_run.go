@@ -0,0 +1,68 @@
+package types
+
+import (
+	"sort"
+	"sync"
+)
+
+var dryRun bool = false
+
+// SetDryRun turns on (or off) dry-run mode. In dry-run mode, nothing is written to
+// disk. Instead, every file generation touches is recorded along with its current
+// and would-be contents.
+func SetDryRun(v bool) {
+	dryRun = v
+}
+
+func IsDryRun() bool {
+	return dryRun
+}
+
+// FileChange describes what generation would do to a single file.
+type FileChange struct {
+	Path   string `json:"path"`
+	Status string `json:"status"` // created, modified, or unchanged
+	Old    string `json:"-"`
+	New    string `json:"-"`
+}
+
+// Diff returns a unified diff between the file's current and would-be contents.
+func (c *FileChange) Diff() string {
+	return UnifiedDiff(c.Path, c.Old, c.New)
+}
+
+var (
+	pendingChanges []FileChange
+	pendingMutex   sync.Mutex
+)
+
+func recordChange(path, oldCode, newCode string, isNew bool) {
+	status := "unchanged"
+	if isNew {
+		status = "created"
+	} else if oldCode != newCode {
+		status = "modified"
+	}
+
+	pendingMutex.Lock()
+	defer pendingMutex.Unlock()
+	pendingChanges = append(pendingChanges, FileChange{Path: path, Status: status, Old: oldCode, New: newCode})
+}
+
+// PendingChanges returns the files a dry run touched sorted by path.
+func PendingChanges() []FileChange {
+	pendingMutex.Lock()
+	defer pendingMutex.Unlock()
+	ret := append([]FileChange{}, pendingChanges...)
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Path < ret[j].Path
+	})
+	return ret
+}
+
+// ResetPendingChanges forgets the changes recorded so far.
+func ResetPendingChanges() {
+	pendingMutex.Lock()
+	defer pendingMutex.Unlock()
+	pendingChanges = nil
+}
@@ -0,0 +1,52 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestDryRunReportsStaleFiles checks that a dry run (which generate --check relies on)
+// reports a hand-edited file as modified without touching it.
+func TestDryRunReportsStaleFiles(t *testing.T) {
+	SetDryRun(true)
+	ResetPendingChanges()
+	defer func() {
+		SetDryRun(false)
+		ResetPendingChanges()
+	}()
+
+	dir := t.TempDir()
+	current := filepath.Join(dir, "current.txt")
+	edited := filepath.Join(dir, "edited.txt")
+	missing := filepath.Join(dir, "missing.txt")
+	for path, contents := range map[string]string{current: "generated\n", edited: "edited by hand\n"} {
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, path := range []string{current, edited, missing} {
+		if _, err := WriteCode(path, "generated\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string]string{current: "unchanged", edited: "modified", missing: "created"}
+	changes := PendingChanges()
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for _, change := range changes {
+		if change.Status != want[change.Path] {
+			t.Errorf("%s: got %s, want %s", change.Path, change.Status, want[change.Path])
+		}
+	}
+
+	if contents, _ := os.ReadFile(edited); string(contents) != "edited by hand\n" {
+		t.Errorf("a dry run wrote to %s", edited)
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("a dry run created %s", missing)
+	}
+}
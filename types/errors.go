@@ -0,0 +1,65 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// Error is returned by the loader and the generator. Op is what was being done (load,
+// generate) and Path, if known, is the input that was being processed when it failed.
+type Error struct {
+	Op   string `json:"op"`
+	Path string `json:"path,omitempty"`
+	Err  error  `json:"-"`
+}
+
+func (e *Error) Error() string {
+	if e.Path != "" {
+		return e.Op + " " + e.Path + ": " + e.Err.Error()
+	}
+	return e.Op + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// wrapError returns err as an *Error unless it is nil or already is one.
+func wrapError(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return &Error{Op: op, Path: path, Err: err}
+}
+
+// fail stops what we are doing with an error. Deep inside the loader and the templates, we
+// cannot return errors, so we panic instead. If a template invoked the failing method,
+// text/template turns the panic into an execution error of that template. Otherwise, it
+// is recovered by recoverError at the API boundary and returned to the caller.
+func fail(format string, args ...any) {
+	panic(fmt.Errorf(format, args...))
+}
+
+// recoverError is deferred by the functions that return errors to callers outside of this
+// package. It converts a panic raised by fail (or by logger.Panic) into an error. Runtime
+// errors are programming errors and are re-raised. It must be deferred directly (recover
+// has no effect in a function called by a deferred function).
+func recoverError(errp *error) {
+	if r := recover(); r != nil {
+		switch v := r.(type) {
+		case runtime.Error:
+			panic(r)
+		case error:
+			*errp = v
+		case string:
+			*errp = errors.New(v)
+		default:
+			panic(r)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// EvalReceiver chooses what Eval applies a template snippet to. At most one of Route,
+// Type, Group, or Facet may be set. If none is, the snippet is applied to the codebase.
+type EvalReceiver struct {
+	Route  string
+	Type   string
+	Group  string
+	Reason string // for groups, readme (the default) or model
+	Facet  string // Type.Facet, for example Transaction.Logs
+}
+
+// Evaluator renders template snippets against a receiver from the codebase in the same
+// way the generator renders templates against it.
+type Evaluator struct {
+	receiver any
+	group    string
+	reason   string
+	facet    string
+	name     string
+}
+
+// NewEvaluator returns an Evaluator for the given receiver.
+func (cb *CodeBase) NewEvaluator(r EvalReceiver) (*Evaluator, error) {
+	set := 0
+	for _, v := range []string{r.Route, r.Type, r.Group, r.Facet} {
+		if v != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return nil, fmt.Errorf("choose only one of route, type, group, or facet")
+	}
+
+	switch {
+	case r.Route != "":
+		c := cb.findCommand(r.Route)
+		if c == nil {
+			return nil, fmt.Errorf("unknown route %s", r.Route)
+		}
+		return &Evaluator{receiver: c, name: "route " + c.Route}, nil
+
+	case r.Type != "":
+		st := cb.findStructure(Lower(r.Type))
+		if st == nil {
+			return nil, fmt.Errorf("unknown type %s", r.Type)
+		}
+		return &Evaluator{receiver: typeReceiver(st), name: "type " + st.Class}, nil
+
+	case r.Facet != "":
+		parts := strings.Split(r.Facet, ".")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid facet %s (expected Type.Facet)", r.Facet)
+		}
+		st := cb.findStructure(Lower(parts[0]))
+		if st == nil {
+			return nil, fmt.Errorf("unknown type %s", parts[0])
+		}
+		s := typeReceiver(st)
+		names := []string{}
+		for i := range s.Facets {
+			if strings.EqualFold(s.Facets[i].Name, parts[1]) {
+				f := &s.Facets[i]
+				return &Evaluator{receiver: f, facet: f.Name, name: "facet " + st.Class + "." + f.Name}, nil
+			}
+			names = append(names, s.Facets[i].Name)
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("type %s has no facets", st.Class)
+		}
+		return nil, fmt.Errorf("unknown facet %s (expected one of %s)", r.Facet, strings.Join(names, ", "))
+
+	case r.Group != "":
+		reason := r.Reason
+		if reason == "" {
+			reason = "readme"
+		}
+		if reason != "readme" && reason != "model" {
+			return nil, fmt.Errorf("invalid reason %s (expected readme or model)", reason)
+		}
+		names := []string{}
+		for _, g := range cb.GroupList("") {
+			if g.GroupName() == LowerNoSpaces(r.Group) {
+				return &Evaluator{receiver: cb, group: g.GroupName(), reason: reason, name: "group " + g.GroupName() + " (" + reason + ")"}, nil
+			}
+			names = append(names, g.GroupName())
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown group %s (expected one of %s)", r.Group, strings.Join(names, ", "))
+	}
+
+	return &Evaluator{receiver: cb, name: "codebase"}, nil
+}
+
+// String names the receiver, for example route export.
+func (e *Evaluator) String() string {
+	return e.name
+}
+
+// Eval renders the snippet. As in a generator template, [{GROUP}] and [{REASON}] are
+// replaced for groups and --Facet-- is replaced in the result for facets. Errors from
+// parsing or executing the snippet, including panics in the methods it calls, are
+// returned rather than ending the program.
+func (e *Evaluator) Eval(snippet string) (ret string, err error) {
+	defer recoverError(&err)
+
+	snippet = strings.ReplaceAll(snippet, "[{GROUP}]", e.group)
+	snippet = strings.ReplaceAll(snippet, "[{REASON}]", e.reason)
+
+	tmpl, err := template.New("eval").Funcs(getFuncMap()).Parse(snippet)
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, e.receiver); err != nil {
+		return "", err
+	}
+	ret = buffer.String()
+	if e.facet != "" {
+		ret = strings.ReplaceAll(ret, "--Facet--", e.facet)
+	}
+	return ret, nil
+}
+
+func (cb *CodeBase) findCommand(route string) *Command {
+	for i := range cb.Commands {
+		if cb.Commands[i].Route == route {
+			return &cb.Commands[i]
+		}
+	}
+	return nil
+}
+
+// typeReceiver returns a copy of the structure with its members sorted as Generate sorts
+// them before applying type templates.
+func typeReceiver(st *Structure) *Structure {
+	s := *st
+	s.Members = append([]Member{}, s.Members...)
+	sort.Slice(s.Members, func(a, b int) bool {
+		return s.Members[a].SortName() < s.Members[b].SortName()
+	})
+	return &s
+}
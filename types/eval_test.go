@@ -0,0 +1,49 @@
+package types
+
+import "testing"
+
+func TestEvaluator(t *testing.T) {
+	cb := &CodeBase{
+		Commands: []Command{
+			{Group: "Chain Data"},
+			{Group: "Chain Data", Route: "blocks"},
+		},
+		Structures: []Structure{{
+			Class:   "Block",
+			Members: []Member{{Name: "timestamp"}, {Name: "hash"}},
+			Facets:  []Facet{{Name: "Logs"}},
+		}},
+	}
+
+	tests := []struct {
+		receiver EvalReceiver
+		snippet  string
+		want     string
+	}{
+		{EvalReceiver{}, "{{len .Commands}}", "2"},
+		{EvalReceiver{Route: "blocks"}, "{{.Route}} {{toUpper .Group}}", "blocks CHAIN DATA"},
+		{EvalReceiver{Type: "Block"}, "{{range .Members}}{{.Name}} {{end}}", "hash timestamp "},
+		{EvalReceiver{Facet: "block.logs"}, "{{.Name}} --Facet--", "Logs Logs"},
+		{EvalReceiver{Group: "chaindata", Reason: "model"}, "[{GROUP}] [{REASON}]", "chaindata model"},
+	}
+	for _, tt := range tests {
+		e, err := cb.NewEvaluator(tt.receiver)
+		if err != nil {
+			t.Fatalf("%+v: %v", tt.receiver, err)
+		}
+		if got, err := e.Eval(tt.snippet); err != nil || got != tt.want {
+			t.Errorf("%s: Eval(%q) = %q, %v; want %q", e, tt.snippet, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []EvalReceiver{{Route: "nope"}, {Type: "Block", Route: "blocks"}, {Facet: "Block"}, {Group: "nope"}} {
+		if _, err := cb.NewEvaluator(bad); err == nil {
+			t.Errorf("expected an error for %+v", bad)
+		}
+	}
+
+	e, _ := cb.NewEvaluator(EvalReceiver{})
+	if _, err := e.Eval("{{.Nope}}"); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
@@ -0,0 +1,239 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
+)
+
+// LineSource tells where in its template a line of a generated file came from.
+type LineSource struct {
+	Line         int    `json:"line"`
+	Text         string `json:"text"`
+	Template     string `json:"template"`
+	TemplateLine int    `json:"templateLine"`
+	Source       string `json:"source"`
+	// Exact is false if the template did not render this line as it appears in the file
+	// (for example, it is inside an EXISTING_CODE block or was changed by the formatter).
+	// TemplateLine is then the source of the closest preceding line that it did render.
+	Exact bool `json:"exact"`
+}
+
+// inputs returns the intro, notes, and partial files (other than the template itself)
+// that the output depends on and that exist.
+func (cb *CodeBase) inputs(o Output) []string {
+	receiver := "codebase"
+	switch o.Scope {
+	case "groups":
+		receiver = o.Group + "/" + o.Reason
+	case "routes":
+		receiver = o.Route
+	case "types":
+		if st := cb.findStructure(o.Type); st != nil {
+			receiver = st.Class
+		}
+	}
+
+	ret := []string{}
+	deps := dependencies(o.Template, o.Scope, receiver, "")
+	for path, hash := range deps.Inputs {
+		if hash != "" && path != deps.Template {
+			ret = append(ret, path)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (cb *CodeBase) findStructure(name string) *Structure {
+	for i := range cb.Structures {
+		if cb.Structures[i].Name() == name {
+			return &cb.Structures[i]
+		}
+	}
+	return nil
+}
+
+// ExplainLine reports which line of o's template produced the given line (counting from
+// one) of the generated file. It renders the template again, without writing anything,
+// and aligns the result with the file on disk.
+func (cb *CodeBase) ExplainLine(o Output, line int) (ret *LineSource, err error) {
+	defer recoverError(&err)
+
+	lines := splitLines(file.AsciiFileToString(o.Path))
+	if line < 1 || line > len(lines) {
+		return nil, fmt.Errorf("%s has %d lines", cleanOutputPath(o.Path), len(lines))
+	}
+
+	rendered, sources := cb.renderWithSources(o)
+
+	// Align the rendered lines with the file ignoring differences in white space
+	normalize := func(lines []string) []string {
+		ret := make([]string, len(lines))
+		for i, l := range lines {
+			ret[i] = strings.Join(strings.Fields(l), " ")
+		}
+		return ret
+	}
+	ops := diffLines(normalize(rendered), normalize(lines))
+
+	templateLine, exact := 0, false
+	r, f := 0, 0
+	for _, op := range ops {
+		if f >= line {
+			break
+		}
+		switch op.kind {
+		case ' ':
+			templateLine = sources[r]
+			exact = f == line-1
+			r++
+			f++
+		case '-':
+			r++
+		case '+':
+			exact = false
+			f++
+		}
+	}
+
+	templateLines := strings.Split(file.AsciiFileToString(o.Template), "\n")
+	source := ""
+	if templateLine > 0 && templateLine <= len(templateLines) {
+		source = templateLines[templateLine-1]
+	}
+	return &LineSource{
+		Line:         line,
+		Text:         lines[line-1],
+		Template:     o.Template,
+		TemplateLine: templateLine,
+		Source:       source,
+		Exact:        exact,
+	}, nil
+}
+
+// renderWithSources renders o's template (before any EXISTING_CODE merging or formatting)
+// and returns its lines along with the line of the template file each came from.
+func (cb *CodeBase) renderWithSources(o Output) ([]string, []int) {
+	route, typ := o.Route, o.Type
+	var receiver any = cb
+	switch o.Scope {
+	case "routes":
+		if c := cb.findCommand(o.Route); c != nil {
+			receiver = c
+		}
+	case "types":
+		st := cb.findStructure(o.Type)
+		if st == nil {
+			fail("unknown type %s", o.Type)
+		}
+		s := typeReceiver(st)
+		receiver = s
+		for i := range s.Facets {
+			if s.Facets[i].Name == o.Facet {
+				receiver = &s.Facets[i]
+			}
+		}
+		route = s.Route
+		if route == "" {
+			route = strings.ToLower(s.Class)
+		}
+	}
+
+	tmplCode, _ := getGeneratorContentsAndDest(o.Template, o.Scope, o.Group, o.Reason, route, typ, o.Group)
+	offset := metadataLines(file.AsciiFileToString(o.Template))
+
+	tmpl, err := template.New("explain").Funcs(getFuncMap()).Parse(tmplCode)
+	if err != nil {
+		fail("parsing template failed: %w", err)
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			markLines(t.Tree.Root, tmplCode, offset)
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, receiver); err != nil {
+		fail("executing template failed: %w", err)
+	}
+
+	// Each line belongs to the template line in effect where it starts
+	lines, sources := []string{}, []int{}
+	current := offset + 1
+	for _, l := range splitLines(buffer.String()) {
+		start := current
+		text := strings.Builder{}
+		for {
+			i := strings.IndexByte(l, lineMarkStart)
+			if i < 0 {
+				text.WriteString(l)
+				break
+			}
+			j := strings.IndexByte(l[i:], lineMarkEnd) + i
+			current, _ = strconv.Atoi(l[i+1 : j])
+			if i == 0 && text.Len() == 0 {
+				start = current
+			}
+			text.WriteString(l[:i])
+			l = l[j+1:]
+		}
+		lines = append(lines, text.String())
+		sources = append(sources, start)
+	}
+	return lines, sources
+}
+
+const (
+	lineMarkStart = '\x00'
+	lineMarkEnd   = '\x01'
+)
+
+func lineMark(line int) string {
+	return string(lineMarkStart) + strconv.Itoa(line) + string(lineMarkEnd)
+}
+
+// markLines inserts a marker carrying the template line at the start of every line of
+// literal text in the template.
+func markLines(node parse.Node, code string, offset int) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			markLines(child, code, offset)
+		}
+	case *parse.IfNode:
+		markLines(n.List, code, offset)
+		markLines(n.ElseList, code, offset)
+	case *parse.RangeNode:
+		markLines(n.List, code, offset)
+		markLines(n.ElseList, code, offset)
+	case *parse.WithNode:
+		markLines(n.List, code, offset)
+		markLines(n.ElseList, code, offset)
+	case *parse.TextNode:
+		pos := int(n.Pos)
+		if pos > len(code) {
+			return
+		}
+		line := offset + strings.Count(code[:pos], "\n") + 1
+		var sb strings.Builder
+		sb.WriteString(lineMark(line))
+		for _, c := range string(n.Text) {
+			sb.WriteRune(c)
+			if c == '\n' {
+				line++
+				sb.WriteString(lineMark(line))
+			}
+		}
+		n.Text = []byte(sb.String())
+	}
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
+)
+
+func TestExplainLine(t *testing.T) {
+	cb, templates := loadTemplatesCopy(t)
+	template := filepath.Join(templates, "generators", "routes", "src_explain+route.txt.tmpl")
+	writeFile(t, template, `/*
+output: explain/[[route]].txt
+scope: route
+*/
+Route: {{.Route}}
+{{- range .Options}}
+option {{.LongName}}
+{{- end}}
+Tool: {{.Tool}}
+The end.
+`)
+
+	var blocks *Command
+	for i := range cb.Commands {
+		if cb.Commands[i].Route == "blocks" {
+			blocks = &cb.Commands[i]
+		}
+	}
+	if blocks == nil || len(blocks.Options) < 2 {
+		t.Fatal("no blocks command with options")
+	}
+
+	outputs, err := cb.Outputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var output *Output
+	for i := range outputs {
+		if outputs[i].Template == template && outputs[i].Route == "blocks" {
+			output = &outputs[i]
+		}
+	}
+	if output == nil {
+		t.Fatal("the template produces no output for blocks")
+	}
+
+	// The file on disk differs from what the template renders by its white space and an
+	// added line
+	lines := []string{"Route:   blocks"}
+	for _, op := range blocks.Options {
+		lines = append(lines, "option "+op.LongName)
+	}
+	lines = append(lines, "Tool: "+blocks.Tool, "a line added by hand", "The end.")
+	writeFile(t, inOutputRoot(output.Path), strings.Join(lines, "\n")+"\n")
+
+	// The template's lines are counted from the start of the file (including its metadata)
+	n := len(lines)
+	tests := []struct {
+		line         int
+		templateLine int
+		exact        bool
+	}{
+		{1, 5, true},
+		{2, 7, true},
+		{n - 3, 7, true},
+		{n - 2, 9, true},
+		{n - 1, 9, false},
+		{n, 10, true},
+	}
+	for _, tt := range tests {
+		got, err := cb.ExplainLine(*output, tt.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.TemplateLine != tt.templateLine || got.Exact != tt.exact || got.Text != lines[tt.line-1] {
+			t.Errorf("line %d: got %+v, want template line %d (exact %t)", tt.line, got, tt.templateLine, tt.exact)
+		}
+		if source := strings.Split(file.AsciiFileToString(template), "\n")[tt.templateLine-1]; got.Source != source {
+			t.Errorf("line %d: got source %q, want %q", tt.line, got.Source, source)
+		}
+	}
+
+	if _, err := cb.ExplainLine(*output, n+1); err == nil {
+		t.Error("expected an error for a line past the end of the file")
+	}
+}
@@ -0,0 +1,191 @@
+package types
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
+)
+
+// mixinFolder is the folder (in classDefinitions) holding the member blocks structures
+// may include with mixins. Each is a .toml file containing only [[members]].
+const mixinFolder = "mixins"
+
+// resolveMembers merges each structure's inherited members into its own. The members of the
+// structure it extends (themselves resolved) come first, then those of each mixin in the
+// order listed, less any that are excluded, and then the structure's own members. A member
+// with the same name as an earlier one replaces it in place. docOrder is renumbered so that
+// documented members appear in the same order: those inherited first, then those of each
+// mixin, and then the structure's own, each in their original docOrder.
+func resolveMembers(roots []string, structMap map[string]Structure) {
+	mixins := map[string][]Member{}
+	loadMixin := func(st *Structure, name string) ([]Member, bool) {
+		if members, ok := mixins[name]; ok {
+			return members, members != nil
+		}
+		// The last classDefinitions folder with the mixin supplies it
+		path := filepath.Join(roots[0], mixinFolder, name+".toml")
+		for _, root := range roots[1:] {
+			if p := filepath.Join(root, mixinFolder, name+".toml"); file.FileExists(p) {
+				path = p
+			}
+		}
+		var f struct {
+			Members []Member `toml:"members"`
+		}
+		if !file.FileExists(path) {
+			reportError(st.pos.withKey("settings.mixins"), "unknown mixin %s in structure %s (expected %s)", name, st.Class, cleanOutputPath(path))
+			mixins[name] = nil
+			return nil, false
+		} else if err := readClassDefinition(path, &f); err != nil {
+			reportError(Position{File: path}, "%v", err)
+			mixins[name] = nil
+			return nil, false
+		}
+		lines := arrayTableLines(path, "members")
+		members := make([]Member, 0, len(f.Members))
+		for i, m := range f.Members {
+			pos := Position{File: path, Key: fmt.Sprintf("members[%d]", i)}
+			if i < len(lines) {
+				pos.Line = lines[i]
+			}
+			m.setPos(pos)
+			_, _ = readMember(&m, nil)
+			if !m.Validate() {
+				reportError(pos, "member %d of mixin %s has no name or type", i+1, name)
+				continue
+			}
+			m.Num = len(members) + 1
+			members = append(members, m)
+		}
+		mixins[name] = members
+		return members, true
+	}
+
+	const (
+		resolving = 1
+		resolved  = 2
+	)
+	state := map[string]int{}
+	var resolve func(key string) bool
+	resolve = func(key string) bool {
+		switch state[key] {
+		case resolved:
+			return true
+		case resolving:
+			return false
+		}
+		st := structMap[key]
+		if st.Extends == "" && len(st.Mixins) == 0 && len(st.Exclude) == 0 {
+			state[key] = resolved
+			return true
+		}
+		state[key] = resolving
+
+		// Members are merged in the order they are defined. Each remembers the source (and
+		// docOrder) of the slot it fills, which orders the renumbered docOrders.
+		type slot struct {
+			rank, docOrder int
+		}
+		merged := []Member{}
+		slots := []slot{}
+		rank := 0
+		add := func(members []Member) {
+			members = append([]Member{}, members...)
+			sort.SliceStable(members, func(i, j int) bool {
+				return members[i].Num < members[j].Num
+			})
+			for _, m := range members {
+				if i := memberIndex(merged, m.Name); i >= 0 {
+					merged[i] = m
+				} else {
+					merged = append(merged, m)
+					slots = append(slots, slot{rank, m.DocOrder})
+				}
+			}
+			rank++
+		}
+
+		if st.Extends != "" {
+			baseKey := classKey(structMap, st.Extends)
+			if baseKey == "" {
+				reportError(st.pos.withKey("settings.extends"), "structure %s extends unknown structure %s", st.Class, st.Extends)
+			} else if !resolve(baseKey) {
+				reportError(st.pos.withKey("settings.extends"), "structure %s extends %s, which extends it in turn", st.Class, st.Extends)
+				st.Extends = ""
+			} else {
+				add(structMap[baseKey].Members)
+			}
+		}
+		for _, name := range st.Mixins {
+			if members, ok := loadMixin(&st, name); ok {
+				add(members)
+			}
+		}
+		for _, name := range st.Exclude {
+			if i := memberIndex(merged, name); i >= 0 {
+				merged = append(merged[:i], merged[i+1:]...)
+				slots = append(slots[:i], slots[i+1:]...)
+			} else {
+				reportWarning(st.pos.withKey("settings.exclude"), "structure %s excludes %s, which it does not inherit", st.Class, name)
+			}
+		}
+		add(st.Members)
+
+		documented := []int{}
+		for i := range merged {
+			merged[i].Num = i + 1
+			if merged[i].DocOrder > 0 {
+				documented = append(documented, i)
+			}
+		}
+		sort.SliceStable(documented, func(a, b int) bool {
+			sa, sb := slots[documented[a]], slots[documented[b]]
+			if sa.rank != sb.rank {
+				return sa.rank < sb.rank
+			}
+			return sa.docOrder < sb.docOrder
+		})
+		for order, i := range documented {
+			merged[i].DocOrder = order + 1
+		}
+		sortMembers(merged)
+		st.Members = merged
+		structMap[key] = st
+		state[key] = resolved
+		return true
+	}
+
+	keys := make([]string, 0, len(structMap))
+	for key := range structMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		resolve(key)
+	}
+}
+
+// classKey returns the key in structMap of the structure with the given class name.
+func classKey(structMap map[string]Structure, class string) string {
+	if _, ok := structMap[strings.ToLower(class)]; ok {
+		return strings.ToLower(class)
+	}
+	for key, st := range structMap {
+		if strings.EqualFold(st.Class, class) {
+			return key
+		}
+	}
+	return ""
+}
+
+func memberIndex(members []Member, name string) int {
+	for i := range members {
+		if members[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,85 @@
+package types
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveMembers(t *testing.T) {
+	ResetDiagnostics()
+	defer ResetDiagnostics()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"block.toml": "[settings]\nclass = \"Block\"\ncache_by = \"block\"\n",
+		"fields/block.csv": `name,type,attributes,docOrder,description
+hash,hash,,1,the hash
+author,address,removed,,
+blockNumber,blknum,,2,the number
+transactions,[]Transaction,,4,the transactions
+uncles,[]hash,,3,the uncles
+`,
+		"lightblock.toml": `[settings]
+class = "LightBlock"
+extends = "Block"
+mixins = ["timestamps"]
+exclude = ["uncles"]
+cache_by = "block"
+
+[[members]]
+name = "transactions"
+type = "[]string"
+docOrder = 1
+description = "the hashes of the transactions"
+
+[[members]]
+name = "size"
+type = "uint64"
+docOrder = 2
+description = "the size"
+`,
+		"mixins/timestamps.toml": `[[members]]
+name = "timestamp"
+type = "timestamp"
+docOrder = 1
+description = "the timestamp"
+`,
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	structures, err := LoadClassDefinitions(ClassDefinitionOptions{RootPath: dir, LoadMembers: true})
+	if err != nil {
+		t.Fatal(err, Diagnostics())
+	}
+	if len(structures) != 2 {
+		t.Fatalf("expected two structures (not the mixin), got %d", len(structures))
+	}
+
+	got := []string{}
+	for _, m := range structures[1].Members {
+		got = append(got, fmt.Sprintf("%s:%s:%d", m.Name, m.Type, m.DocOrder))
+	}
+	want := "hash:hash:1 author:address:0 blockNumber:blknum:2 transactions:string:3 timestamp:timestamp:4 size:uint64:5"
+	if strings.Join(got, " ") != want {
+		t.Errorf("unexpected members\n got: %s\nwant: %s", strings.Join(got, " "), want)
+	}
+
+	cb := CodeBase{Structures: structures}
+	for i := range cb.Structures {
+		cb.Structures[i].cbPtr = &cb
+	}
+	if loc := cb.Structures[1].CacheLoc(); loc != "Block" {
+		t.Errorf("expected LightBlock to be cached with Block, got %s", loc)
+	}
+}
@@ -2,14 +2,14 @@ package types
 
 import (
 	"fmt"
-	"os"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/colors"
 	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
 	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/logger"
-	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/walk"
 )
 
 type Generator struct {
@@ -17,152 +17,302 @@ type Generator struct {
 	Templates []string `json:"templates"`
 }
 
+// workers is the number of outputs generated at the same time
+var workers = runtime.NumCPU()
+
+// SetWorkers sets the number of outputs generated at the same time. Values less than one
+// mean one.
+func SetWorkers(n int) {
+	workers = max(1, n)
+}
+
+// GetWorkers returns the number of outputs generated at the same time.
+func GetWorkers() int {
+	return workers
+}
+
+// runJobs runs the jobs using at most nWorkers goroutines. It waits for all of them to
+// finish and returns the error of the first failing job in the order given (not the
+// order in which they failed) so that the result does not depend on scheduling.
+func runJobs(jobs []func() error, nWorkers int) error {
+	errs := make([]error, len(jobs))
+	if nWorkers <= 1 {
+		for i, job := range jobs {
+			if errs[i] = job(); errs[i] != nil {
+				return errs[i]
+			}
+		}
+		return nil
+	}
+
+	sem := make(chan struct{}, nWorkers)
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = job()
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Generate generates the code for the codebase using the given templates.
 func (cb *CodeBase) Generate() {
+	if err := cb.GenerateOnly(nil); err != nil {
+		logger.Fatal(err)
+	}
+}
+
+// Targets limits what GenerateOnly produces. Each template listed in Templates is applied
+// to every receiver in its scope. Otherwise, codebase templates run if Codebase is set and
+// group, route, and type templates run for the listed groups, routes, and types.
+type Targets struct {
+	Templates map[string]bool `json:"templates,omitempty"`
+	Codebase  bool            `json:"codebase,omitempty"`
+	Groups    map[string]bool `json:"groups,omitempty"`
+	Routes    map[string]bool `json:"routes,omitempty"`
+	Types     map[string]bool `json:"types,omitempty"`
+}
+
+// IsEmpty returns true if the targets select nothing.
+func (t *Targets) IsEmpty() bool {
+	return len(t.Templates) == 0 && !t.Codebase && len(t.Groups) == 0 && len(t.Routes) == 0 && len(t.Types) == 0
+}
+
+// wants returns true if the template at fullPath should be applied to the named receiver.
+// A nil *Targets wants everything.
+func (t *Targets) wants(fullPath, against, name string) bool {
+	if t == nil || t.Templates[fullPath] {
+		return true
+	}
+	switch against {
+	case "codebase":
+		return t.Codebase
+	case "groups":
+		return t.Groups[name]
+	case "routes":
+		return t.Routes[name]
+	case "types":
+		return t.Types[name]
+	}
+	return false
+}
+
+// GenerateOnly generates the outputs selected by targets (or everything if targets is nil).
+// It stops at and returns the first error, including errors in the templates themselves.
+func (cb *CodeBase) GenerateOnly(targets *Targets) (err error) {
+	defer func() { err = wrapError("generate", "", err) }()
+	defer recoverError(&err)
+
 	VerboseLog("Starting code generation process")
 
 	// Validate that the necessary files and folders exist
 	if err := cb.isValidSetup(); err != nil {
-		logger.Fatal(err)
+		return err
 	}
 
 	// Before we start, we need to verify that the validators are in place
-	cb.verifyValidators()
+	found, err := cb.VerifyValidators()
+	for _, d := range found {
+		logger.Warn(d.String())
+	}
+	if err != nil {
+		return err
+	}
 
 	generatedPath := GetGeneratedPath()
 	if !file.FolderExists(generatedPath) {
-		logger.Fatal(fmt.Sprintf("generatedPath %s is empty", generatedPath))
+		return fmt.Errorf("generatedPath %s is empty", generatedPath)
+	}
+	if !dryRun {
+		VerboseLog("Creating generated code directory at", generatedPath)
+		_ = file.EstablishFolder(generatedPath)
 	}
-	VerboseLog("Creating generated code directory at", generatedPath)
-	_ = file.EstablishFolder(generatedPath)
 
 	generators, err := getGenerators()
 	if err != nil {
-		logger.Fatal(err)
+		return err
+	}
+
+	if !dryRun {
+		loadDependencyGraph()
+		resetProduced()
+		defer func() {
+			if err := saveDependencyGraph(); err != nil {
+				logger.Warn("could not save the dependency graph:", err)
+			}
+		}()
+		setFingerprint(cb)
 	}
 
 	VerboseLog("Processing generators")
 	for _, generator := range generators {
 		VerboseLog("Processing", generator.Against, "templates")
+		fullPath := func(source string) string {
+			return getGeneratorPath(generator.Against, source)
+		}
+
+		// Codebase and group templates sort the codebase's commands and structures in place,
+		// so they run one at a time. Route and type templates run in parallel.
+		jobs := []func() error{}
+		nWorkers := workers
 		switch generator.Against {
 		case "codebase":
+			nWorkers = 1
 			for _, source := range generator.Templates {
-				VerboseLog("Processing codebase template:", source)
-				if err := cb.ProcessFile(source, "", ""); err != nil {
-					logger.Fatal(err)
+				if !targets.wants(fullPath(source), generator.Against, "codebase") {
+					continue
 				}
+				jobs = append(jobs, func() error {
+					VerboseLog("Processing codebase template:", source)
+					return wrapError("generate", fullPath(source), cb.ProcessFile(source, "", ""))
+				})
 			}
 		case "groups":
-			for _, source := range generator.Templates {
-				VerboseLog("Processing group template:", source)
-				for _, group := range cb.GroupList("") {
-					VerboseLog("  - For group:", group.GroupName())
-					if err := cb.ProcessGroupFile(source, group.GroupName(), "readme"); err != nil {
-						logger.Fatal(err)
-					}
-				}
-			}
-			for _, source := range generator.Templates {
-				VerboseLog("Processing group model template:", source)
-				for _, group := range cb.GroupList("") {
-					if err := cb.ProcessGroupFile(source, group.GroupName(), "model"); err != nil {
-						logger.Fatal(err)
+			nWorkers = 1
+			for _, reason := range []string{"readme", "model"} {
+				for _, source := range generator.Templates {
+					for _, group := range cb.GroupList("") {
+						if !targets.wants(fullPath(source), generator.Against, group.GroupName()) {
+							continue
+						}
+						jobs = append(jobs, func() error {
+							VerboseLog("Processing group template:", source, "for group:", group.GroupName(), "reason:", reason)
+							return wrapError("generate", fullPath(source), cb.ProcessGroupFile(source, group.GroupName(), reason))
+						})
 					}
 				}
 			}
 		case "routes":
 			for _, source := range generator.Templates {
-				VerboseLog("Processing route template:", source)
 				for _, c := range cb.Commands {
-					VerboseLog("  - For command:", c.Route)
-					if err := c.ProcessFile(source, "", ""); err != nil {
-						logger.Fatal(err)
+					if !targets.wants(fullPath(source), generator.Against, c.Route) {
+						continue
 					}
-				}
-			}
-		case "types":
-			for _, source := range generator.Templates {
-				VerboseLog("Processing type template:", source)
-				for _, s := range cb.Structures {
-					sort.Slice(s.Members, func(i, j int) bool {
-						return s.Members[i].SortName() < s.Members[j].SortName()
+					jobs = append(jobs, func() error {
+						VerboseLog("Processing route template:", source, "for command:", c.Route)
+						return wrapError("generate", fullPath(source), c.ProcessFile(source, "", ""))
 					})
-					if !s.DisableGo {
-						VerboseLog("  - For type:", s.Name())
-						if err := s.ProcessFile(source, "", ""); err != nil {
-							logger.Fatal(err)
-						}
-					}
 				}
 			}
+		case "types":
+			jobs = cb.typeJobs(generator, targets, func(s Structure, source string) error {
+				VerboseLog("Processing type template:", source, "for type:", s.Name())
+				return wrapError("generate", fullPath(source), s.ProcessFile(source, "", ""))
+			})
 		default:
-			logger.Fatal("unknown against value: ", generator.Against)
+			return fmt.Errorf("unknown against value: %s", generator.Against)
+		}
+
+		err = runJobs(jobs, nWorkers)
+		flushLogs()
+		if err != nil {
+			return err
 		}
 	}
+	if !dryRun {
+		if err := writeManifest(isCompleteRun(targets)); err != nil {
+			return err
+		}
+	}
+
+	if skipped > 0 {
+		logger.Info(fmt.Sprintf("Skipped %d outputs whose inputs have not changed", skipped))
+	}
 	logger.Info(colors.Green + "Done..." + strings.Repeat(" ", 120) + colors.Off + "\033[K")
+	return nil
 }
 
-// getGenerators returns the generators we will be using
+// typeJobs returns a job for each of the generator's templates and each structure selected
+// by targets. Each job calls process with its own copy of the structure. Templates may
+// re-sort the members, so the copies do not share them.
+func (cb *CodeBase) typeJobs(generator Generator, targets *Targets, process func(s Structure, source string) error) []func() error {
+	for i := range cb.Structures {
+		sort.Slice(cb.Structures[i].Members, func(a, b int) bool {
+			return cb.Structures[i].Members[a].SortName() < cb.Structures[i].Members[b].SortName()
+		})
+	}
+	jobs := []func() error{}
+	for _, source := range generator.Templates {
+		for _, s := range cb.Structures {
+			if s.DisableGo || !targets.wants(getGeneratorPath(generator.Against, source), generator.Against, s.Class) {
+				continue
+			}
+			s.Members = append([]Member{}, s.Members...)
+			jobs = append(jobs, func() error {
+				return process(s, source)
+			})
+		}
+	}
+	return jobs
+}
+
+// Generators returns the generators (grouped by what they are applied against) that
+// Generate would use.
+func Generators() (ret []Generator, err error) {
+	defer recoverError(&err)
+	return getGenerators()
+}
+
+// getGenerators returns the generators we will be using. The generators folder of each
+// template layer is searched. An overlay's template replaces the one with the same path.
 func getGenerators() ([]Generator, error) {
 	generatorsPath := getGeneratorsPath() + "/"
 	if !file.FolderExists(generatorsPath) {
 		return []Generator{}, fmt.Errorf("generatorsPath (%s) not found", generatorsPath)
 	}
-	VerboseLog("Looking for templates in:", generatorsPath)
+
+	roots := []string{}
+	for _, layer := range generatorLayers() {
+		VerboseLog("Looking for templates in:", layer.generators, "("+layer.name+")")
+		roots = append(roots, layer.generators)
+	}
 
 	theMap := make(map[string][]string)
-	vFunc := func(file string, vP any) (bool, error) {
-		_ = vP
-		isPartial := strings.HasSuffix(file, ".partial.tmpl")
-		isTemplate := strings.HasSuffix(file, ".tmpl") && !isPartial
-		filter := os.Getenv("TB_GENERATOR_FILTER")
-		if len(filter) > 0 && !strings.Contains(file, filter) {
-			return true, nil
+	filter := getFilter()
+	for _, path := range layerFiles(roots, ".tmpl", "") {
+		if strings.HasSuffix(path, ".partial.tmpl") {
+			continue
 		}
-		if isTemplate {
-			VerboseLog("  Found template:", file)
-			// Strip the generators path prefix to get relative path from generators/
-			relPath := file
-
-			// Find the index of "generators/" in the path
-			generatorsIndex := strings.Index(file, "generators/")
-			if generatorsIndex != -1 {
-				// Extract path after "generators/"
-				relPath = file[generatorsIndex+len("generators/"):]
-			}
-
-			if strings.Contains(relPath, string(os.PathSeparator)) {
-				parts := strings.Split(relPath, string(os.PathSeparator))
-				category := parts[0] // This should be codebase, routes, types, or groups
-				theMap[category] = append(theMap[category], file)
-			}
+		if len(filter) > 0 && !strings.Contains(path, filter) {
+			continue
+		}
+		relPath, _ := generatorRel(path)
+		if layer := layerOf(path); layer != "base" {
+			VerboseLog("  Found template:", path, "(from overlay "+layer+")")
+		} else {
+			VerboseLog("  Found template:", path)
+		}
+		// The first folder is the category (codebase, routes, types, or groups)
+		if category, template, ok := strings.Cut(relPath, "/"); ok {
+			theMap[category] = append(theMap[category], template)
 		}
-		return true, nil
 	}
 
-	_ = walk.ForEveryFileInFolder(generatorsPath, vFunc, nil)
-
 	ret := []Generator{}
 	for against, templates := range theMap {
+		if !isCategoryEnabled(against) {
+			VerboseLog("  Skipping", against, "generators (not enabled in", ProjectConfigFile+")")
+			continue
+		}
 		VerboseLog("  Creating generator for:", against, "with templates:", templates)
 		g := Generator{
 			Against: against,
 		}
 		sort.Strings(templates)
-		for _, template := range templates {
-			// Extract just the filename from the full path
-			// Find the index of "generators/" in the path and get path after it
-			generatorsIndex := strings.Index(template, "generators/")
-			if generatorsIndex != -1 {
-				// Get path after "generators/"
-				relPath := template[generatorsIndex+len("generators/"):]
-				// Remove the category part (against/) to get just the template filename
-				if strings.HasPrefix(relPath, against+"/") {
-					template = strings.TrimPrefix(relPath, against+"/")
-				}
-			}
-			g.Templates = append(g.Templates, template)
-		}
+		g.Templates = append(g.Templates, templates...)
 		ret = append(ret, g)
 	}
 	sort.Slice(ret, func(i, j int) bool {
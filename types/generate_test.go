@@ -0,0 +1,91 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunJobs(t *testing.T) {
+	// Earlier jobs take longer, so the later ones fail first
+	var ran atomic.Int32
+	fails := map[int]bool{1: true, 3: true, 4: true}
+	jobs := []func() error{}
+	for i := 0; i < 6; i++ {
+		jobs = append(jobs, func() error {
+			time.Sleep(time.Duration(6-i) * 10 * time.Millisecond)
+			ran.Add(1)
+			if fails[i] {
+				return fmt.Errorf("job %d failed", i)
+			}
+			return nil
+		})
+	}
+
+	for _, nWorkers := range []int{0, 1, 2, 6} {
+		ran.Store(0)
+		err := runJobs(jobs, nWorkers)
+		if err == nil || err.Error() != "job 1 failed" {
+			t.Errorf("workers %d: got %v, want the error of job 1", nWorkers, err)
+		}
+		want := int32(len(jobs))
+		if nWorkers <= 1 {
+			want = 2 // one at a time stops at the first failure
+		}
+		if got := ran.Load(); got != want {
+			t.Errorf("workers %d: %d jobs ran, want %d", nWorkers, got, want)
+		}
+	}
+
+	if err := runJobs(jobs[:1], 4); err != nil {
+		t.Errorf("got %v, want no error", err)
+	}
+	if err := runJobs(nil, 4); err != nil {
+		t.Errorf("got %v, want no error", err)
+	}
+}
+
+func TestTypeJobsCopyMembers(t *testing.T) {
+	cb, _ := loadTemplatesCopy(t)
+
+	owners := map[*Member]string{}
+	for _, s := range cb.Structures {
+		if len(s.Members) > 0 {
+			owners[&s.Members[0]] = "the codebase's " + s.Class
+		}
+	}
+
+	generator := Generator{Against: "types", Templates: []string{"a.go.tmpl", "b.go.tmpl"}}
+	jobs := cb.typeJobs(generator, nil, func(s Structure, source string) error {
+		if len(s.Members) == 0 {
+			return nil
+		}
+		if owner, ok := owners[&s.Members[0]]; ok {
+			return fmt.Errorf("%s for %s shares its members with %s", s.Class, source, owner)
+		}
+		owners[&s.Members[0]] = s.Class + " for " + source
+		return nil
+	})
+	if len(jobs) == 0 {
+		t.Fatal("expected some jobs")
+	}
+	if err := runJobs(jobs, 1); err != nil {
+		t.Error(err)
+	}
+
+	target := &Targets{Types: map[string]bool{"Block": true}}
+	jobs = cb.typeJobs(generator, target, func(s Structure, source string) error {
+		if s.Class != "Block" {
+			return errors.New("unexpected type " + s.Class)
+		}
+		return nil
+	})
+	if len(jobs) != len(generator.Templates) {
+		t.Errorf("got %d jobs, want one per template", len(jobs))
+	}
+	if err := runJobs(jobs, 1); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,132 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var suggestHotKeys = false
+
+// SetSuggestHotKeys makes hotkey collisions suggest letters that are free.
+func SetSuggestHotKeys(v bool) {
+	suggestHotKeys = v
+}
+
+// menuHotKeys returns the hotkey and altHotkey of the n-th item of a menu (counting from
+// one). Only the first twenty items have hotkeys of their own.
+func menuHotKeys(n int) (string, string) {
+	var key string
+	if n == 10 {
+		key = "0"
+	} else {
+		key = fmt.Sprintf("%d", n%10)
+	}
+	if n > 10 {
+		return fmt.Sprintf("mod+shift+%s", key), fmt.Sprintf("alt+shift+%s", key)
+	}
+	return fmt.Sprintf("mod+%s", key), fmt.Sprintf("alt+%s", key)
+}
+
+// checkHotKeys reports options that use the same hotkey as another option of their command
+// or as one of the globals the command accepts, and data models whose ui_route hotkeys (or
+// menu hotkeys) collide.
+func (cb *CodeBase) checkHotKeys() {
+	for _, c := range cb.Commands {
+		if c.Route == "" {
+			continue
+		}
+
+		globalKeys := map[string]string{}
+		taken := map[string]bool{}
+		for _, glob := range c.commandGlobals() {
+			if glob.HotKey != "" {
+				globalKeys[glob.HotKey] = glob.LongName
+				taken[glob.HotKey] = true
+			}
+		}
+		for _, op := range c.Options {
+			if op.HotKey != "" {
+				taken[op.HotKey] = true
+			}
+		}
+
+		first := map[string]Option{}
+		for _, op := range c.Options {
+			if op.HotKey == "" {
+				continue
+			}
+			if glob, ok := globalKeys[op.HotKey]; ok {
+				reportError(op.pos.withKey("hotKey"), "hotkey -%s of --%s in %s is already used by the global --%s%s", op.HotKey, op.LongName, c.Route, glob, freeHotKeys(op.LongName, taken))
+			} else if other, ok := first[op.HotKey]; ok {
+				reportError(op.pos.withKey("hotKey"), "hotkey -%s of --%s in %s is already used by --%s (%s)%s", op.HotKey, op.LongName, c.Route, other.LongName, other.pos, freeHotKeys(op.LongName, taken))
+			} else {
+				first[op.HotKey] = op
+			}
+		}
+	}
+
+	// Data models with a ui_route appear in the menu in the order of their ui_route numbers
+	uiModels := []*Structure{}
+	for i := range cb.Structures {
+		if cb.Structures[i].UiRoute != "" {
+			uiModels = append(uiModels, &cb.Structures[i])
+		}
+	}
+	sort.SliceStable(uiModels, func(i, j int) bool {
+		ni, _ := strconv.Atoi(uiModels[i].getUiRoutePart(0))
+		nj, _ := strconv.Atoi(uiModels[j].getUiRoutePart(0))
+		return ni < nj
+	})
+
+	taken := map[string]bool{}
+	for _, st := range uiModels {
+		taken[st.UiHotKey()] = true
+	}
+	first := map[string]*Structure{}
+	menuKeys := map[string]*Structure{}
+	for n, st := range uiModels {
+		if key := st.UiHotKey(); key != "none" {
+			if other, seen := first[key]; seen {
+				reportError(st.pos.withKey("settings.ui_route"), "ui_route hotkey %s of %s is already used by %s (%s)%s", key, st.Class, other.Class, other.pos, freeHotKeys(st.UiRouteName(), taken))
+			} else {
+				first[key] = st
+			}
+		}
+		hotkey, _ := menuHotKeys(n + 1)
+		if other, seen := menuKeys[hotkey]; seen {
+			reportError(st.pos.withKey("settings.ui_route"), "menu hotkey %s of %s (item %d) is already used by %s; only the first 20 menu items have hotkeys of their own", hotkey, st.Class, n+1, other.Class)
+		} else {
+			menuKeys[hotkey] = st
+		}
+	}
+}
+
+// freeHotKeys returns (if asked to suggest hotkeys) a note listing a few letters that are
+// not taken, preferring the letters of the given name.
+func freeHotKeys(name string, taken map[string]bool) string {
+	if !suggestHotKeys {
+		return ""
+	}
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	free := []string{}
+	seen := map[rune]bool{}
+	for _, r := range strings.ToLower(name) + strings.ToUpper(name) + letters {
+		key := string(r)
+		if seen[r] || !strings.ContainsRune(letters, r) {
+			continue
+		}
+		seen[r] = true
+		if !taken[key] {
+			free = append(free, key)
+			if len(free) == 5 {
+				break
+			}
+		}
+	}
+	if len(free) == 0 {
+		return " (no letters are free)"
+	}
+	return " (free: " + strings.Join(free, ", ") + ")"
+}
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestCheckHotKeys(t *testing.T) {
+	ResetDiagnostics()
+	defer ResetDiagnostics()
+	SetSuggestHotKeys(true)
+	defer SetSuggestHotKeys(false)
+
+	option := func(line int, longName, hotKey string) Option {
+		return Option{Route: "list", LongName: longName, HotKey: hotKey, pos: Position{File: "options.csv", Line: line}}
+	}
+	model := func(class, uiRoute string) Structure {
+		return Structure{Class: class, UiRoute: uiRoute, pos: Position{File: class + ".toml"}}
+	}
+	cb := CodeBase{
+		Commands: []Command{{
+			Route:        "list",
+			Capabilities: "default|caching|",
+			Options: []Option{
+				option(2, "count", "U"),
+				option(3, "no_zero", "U"),
+				option(4, "bounds", "o"),
+				option(5, "unripe", "u"),
+			},
+		}},
+		Structures: []Structure{
+			model("Names", "2-names-n"),
+			model("Monitors", "1-monitors-m"),
+			model("Nodes", "3-nodes-n"),
+		},
+	}
+	cb.checkHotKeys()
+
+	// Diagnostics are sorted by file
+	want := []string{
+		"ui_route hotkey n of Nodes is already used by Names (Names.toml) (free: o, d, e, s, N)",
+		"hotkey -U of --no_zero in list is already used by --count (options.csv:2) (free: n, z, e, r, N)",
+		"hotkey -o of --bounds in list is already used by the global --cache (free: b, n, d, s, B)",
+	}
+	got := Diagnostics()
+	if len(got) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i].Message != want[i] {
+			t.Errorf("diagnostic %d = %q, want %q", i, got[i].Message, want[i])
+		}
+	}
+}
+
+func TestMenuHotKeys(t *testing.T) {
+	for n, want := range map[int]string{1: "mod+1", 10: "mod+0", 11: "mod+shift+1", 20: "mod+shift+0"} {
+		if got, _ := menuHotKeys(n); got != want {
+			t.Errorf("menuHotKeys(%d) = %s, want %s", n, got, want)
+		}
+	}
+}
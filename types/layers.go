@@ -0,0 +1,222 @@
+package types
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
+)
+
+// templateLayer is the templates folder or one of the overlays layered over it. An
+// overlay has the same layout as the templates folder (generators, readme-intros,
+// model-intros, classDefinitions, and so on) but holds only the files it changes or adds.
+type templateLayer struct {
+	name       string // "base" or the overlay's folder
+	templates  string
+	generators string
+}
+
+// templateLayers returns the templates folder followed by each overlay in the order
+// given. A file in a later layer replaces the file with the same relative path in an
+// earlier one. Overlays that do not exist are skipped (see ValidateOverlays). The base
+// layer's generators folder is left empty so that loading the model does not require
+// one. Use generatorLayers when the generators are needed.
+func templateLayers() []templateLayer {
+	ret := []templateLayer{{name: "base", templates: getTemplatePathNoErr()}}
+	for _, overlay := range getOverlaysSetting() {
+		overlay = inOutputRoot(overlay)
+		if file.FolderExists(overlay) {
+			ret = append(ret, templateLayer{name: cleanOutputPath(overlay), templates: overlay, generators: filepath.Join(overlay, "generators")})
+		}
+	}
+	return ret
+}
+
+// generatorLayers returns the templateLayers including the base layer's generators folder.
+// It fails if the generators folder cannot be found.
+func generatorLayers() []templateLayer {
+	ret := templateLayers()
+	ret[0].generators = getGeneratorsPath()
+	return ret
+}
+
+// ValidateOverlays returns an error if a template overlay does not exist.
+func ValidateOverlays() error {
+	for _, overlay := range getOverlaysSetting() {
+		if !file.FolderExists(inOutputRoot(overlay)) {
+			return fmt.Errorf("template overlay %s does not exist", overlay)
+		}
+	}
+	return nil
+}
+
+func hasOverlays() bool {
+	return len(templateLayers()) > 1
+}
+
+// templateFile returns the path of the file (relative to the templates folder) in the
+// last layer that has it. If none does, it returns the path in the templates folder.
+func templateFile(rel string) string {
+	return layerFile(templateLayers(), rel, func(l templateLayer) string { return l.templates })
+}
+
+// generatorFile returns the path of the generator (relative to the generators folder) in
+// the last layer that has it. If none does, it returns the path in the generators folder.
+func generatorFile(rel string) string {
+	return layerFile(generatorLayers(), rel, func(l templateLayer) string { return l.generators })
+}
+
+func layerFile(layers []templateLayer, rel string, root func(templateLayer) string) string {
+	for i := len(layers) - 1; i > 0; i-- {
+		if path := filepath.Join(root(layers[i]), rel); file.FileExists(path) {
+			logLayer(rel, layers[i].name)
+			return path
+		}
+	}
+	return filepath.Join(root(layers[0]), rel)
+}
+
+var (
+	loggedLayers      = map[string]bool{}
+	loggedLayersMutex sync.Mutex
+)
+
+// logLayer reports (once, in verbose mode) a file supplied by an overlay.
+func logLayer(rel, layer string) {
+	loggedLayersMutex.Lock()
+	defer loggedLayersMutex.Unlock()
+	if !loggedLayers[rel] {
+		loggedLayers[rel] = true
+		VerboseLog("  Using", rel, "from overlay", layer)
+	}
+}
+
+// layerFiles returns the files ending with suffix under each of the folders (later
+// folders replacing files with the same relative path) sorted by their relative paths.
+// Folders named skip are not searched.
+func layerFiles(roots []string, suffix, skip string) []string {
+	found := map[string]string{}
+	for _, root := range roots {
+		_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if skip != "" && path == filepath.Join(root, skip) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(path, suffix) {
+				rel, _ := filepath.Rel(root, path)
+				found[rel] = path
+			}
+			return nil
+		})
+	}
+	rels := make([]string, 0, len(found))
+	for rel := range found {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+	ret := make([]string, 0, len(rels))
+	for _, rel := range rels {
+		ret = append(ret, found[rel])
+	}
+	return ret
+}
+
+// templateFolders returns the folder (relative to the templates folder) in each layer that
+// has it.
+func templateFolders(rel string) []string {
+	ret := []string{}
+	for _, l := range templateLayers() {
+		if folder := filepath.Join(l.templates, rel); file.FolderExists(folder) {
+			ret = append(ret, folder)
+		}
+	}
+	return ret
+}
+
+// folderFiles returns the files ending with suffix directly inside folder (relative to the
+// templates folder or, if generators is true, the generators folder) in every layer. A file
+// in a later layer replaces the one with the same name in an earlier layer.
+func folderFiles(rel, suffix string, generators bool) []string {
+	layers := templateLayers()
+	if generators {
+		layers = generatorLayers()
+	}
+	found := map[string]string{}
+	for _, l := range layers {
+		root := l.templates
+		if generators {
+			root = l.generators
+		}
+		entries, _ := os.ReadDir(filepath.Join(root, rel))
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), suffix) {
+				found[entry.Name()] = filepath.Join(root, rel, entry.Name())
+			}
+		}
+	}
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	ret := make([]string, 0, len(names))
+	for _, name := range names {
+		ret = append(ret, found[name])
+	}
+	return ret
+}
+
+// generatorRel returns the path of a generator relative to the generators folder of the
+// layer that holds it.
+func generatorRel(path string) (string, bool) {
+	layers := generatorLayers()
+	for i := len(layers) - 1; i >= 0; i-- {
+		if rel, ok := relativeTo(layers[i].generators, absPath(path)); ok {
+			return rel, true
+		}
+	}
+	return "", false
+}
+
+// templateRel returns the path of a file relative to the templates folder of the layer
+// that holds it.
+func templateRel(path string) (string, bool) {
+	layers := templateLayers()
+	for i := len(layers) - 1; i >= 0; i-- {
+		if rel, ok := relativeTo(layers[i].templates, absPath(path)); ok {
+			return rel, true
+		}
+	}
+	return "", false
+}
+
+// layerOf returns the name of the layer that holds the file (base or an overlay's folder).
+func layerOf(path string) string {
+	layers := generatorLayers()
+	for i := len(layers) - 1; i >= 0; i-- {
+		if _, ok := relativeTo(layers[i].generators, absPath(path)); ok {
+			return layers[i].name
+		}
+		if _, ok := relativeTo(layers[i].templates, absPath(path)); ok {
+			return layers[i].name
+		}
+	}
+	return ""
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
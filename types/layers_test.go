@@ -0,0 +1,103 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLayerFiles(t *testing.T) {
+	base, overlay := t.TempDir(), t.TempDir()
+	files := map[string]string{
+		filepath.Join(base, "types", "a.go.tmpl"):       "base",
+		filepath.Join(base, "types", "b.go.tmpl"):       "base",
+		filepath.Join(base, "mixins", "c.go.tmpl"):      "skipped",
+		filepath.Join(base, "notes.md"):                 "not a template",
+		filepath.Join(overlay, "types", "b.go.tmpl"):    "overlay",
+		filepath.Join(overlay, "routes", "d.go.tmpl"):   "overlay",
+		filepath.Join(overlay, "mixins", "e.go.tmpl"):   "skipped",
+		filepath.Join(overlay, "types", "f.partial.md"): "not a template",
+	}
+	for path, contents := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := layerFiles([]string{base, overlay}, ".tmpl", "mixins")
+	want := []string{
+		filepath.Join(overlay, "routes", "d.go.tmpl"),
+		filepath.Join(base, "types", "a.go.tmpl"),
+		filepath.Join(overlay, "types", "b.go.tmpl"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("layerFiles() = %v, want %v", got, want)
+	}
+}
+
+// copyTemplates copies the repository's templates folder (without the folders named in
+// skip) to a templates folder in dir and returns its path.
+func copyTemplates(t *testing.T, dir string, skip ...string) string {
+	t.Helper()
+	src, err := filepath.Abs("../templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(dir, "templates")
+	err = filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		if d.IsDir() {
+			for _, s := range skip {
+				if rel == s {
+					return filepath.SkipDir
+				}
+			}
+			return os.MkdirAll(filepath.Join(dst, rel), 0o755)
+		}
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), contents, 0o644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dst
+}
+
+// TestLoadWithoutGenerators makes sure the model loads from a templates folder that has no
+// generators folder. Only generating needs one.
+func TestLoadWithoutGenerators(t *testing.T) {
+	dir := t.TempDir()
+	SetTemplatesPath(copyTemplates(t, dir, "generators"))
+	SetOutputRoot(dir)
+	SetDryRun(true)
+	defer func() {
+		SetTemplatesPath("")
+		SetOutputRoot("")
+		SetDryRun(false)
+		ResetPendingChanges()
+		ResetDiagnostics()
+	}()
+
+	cb, err := LoadCodebase()
+	if err != nil {
+		t.Fatalf("loading without a generators folder failed: %v", err)
+	}
+	if len(cb.Commands) == 0 || len(cb.Structures) == 0 {
+		t.Errorf("expected commands and structures, got %d and %d", len(cb.Commands), len(cb.Structures))
+	}
+
+	if _, err := Generators(); err == nil || !strings.Contains(err.Error(), "could not find generators directory") {
+		t.Errorf("expected the generators folder to be required to generate, got %v", err)
+	}
+}
@@ -2,26 +2,26 @@ package types
 
 import (
 	"fmt"
-	"io/fs"
-	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
-	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/config"
 	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
-	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/logger"
 )
 
 // LoadCodebase loads the two csv files and returns the codebase which
 // contains all the commands (each with its own set of options).
-func LoadCodebase() (CodeBase, error) {
+func LoadCodebase() (ret CodeBase, err error) {
+	defer func() { err = wrapError("load", "", err) }()
+	defer recoverError(&err)
+
 	thePath, err := getTemplatePath()
 	if err != nil {
 		return CodeBase{}, err
 	}
 
 	var cb CodeBase
+	ResetDiagnostics()
 
 	baseTypesPath := filepath.Join(thePath, "base-types.csv")
 	if !file.FileExists(baseTypesPath) {
@@ -40,35 +40,109 @@ func LoadCodebase() (CodeBase, error) {
 	if err != nil {
 		return cb, err
 	}
-	err = checkForDups(options)
+	checkForDups(options)
+
+	structMap, err := loadClassDefinitions(ClassDefinitionOptions{
+		IncludeDisabled: true,
+		LoadMembers:     true,
+	})
 	if err != nil {
 		return cb, err
 	}
 
-	classDefPath := filepath.Join(thePath, "classDefinitions/")
-	if !file.FolderExists(classDefPath) {
-		return cb, fmt.Errorf("classDefPath (%s) not found - quitting", classDefPath)
-	}
-	structMap := make(map[string]Structure)
-	err = cb.LoadStructures(classDefPath, readStructure, structMap)
+	err = cb.FinishLoad(thePath, baseTypes, options, structMap)
 	if err != nil {
 		return cb, err
 	}
-	if len(structMap) == 0 {
-		return cb, fmt.Errorf("no structures were loaded from %s - quitting", classDefPath)
-	}
 
-	err = cb.LoadMembers(classDefPath, structMap)
+	return cb, nil
+}
+
+// ClassDefinitionOptions configures LoadClassDefinitions.
+type ClassDefinitionOptions struct {
+	// RootPath is the classDefinitions folder. If empty, it is the one in the templates
+	// folder with the classDefinitions folders of any template overlays laid over it.
+	RootPath string
+	// IncludeDisabled includes structures with disable_go set.
+	IncludeDisabled bool
+	// LoadMembers reads each structure's members from fields/<class>.csv or, if given
+	// there, the [[members]] in its .toml file.
+	LoadMembers bool
+}
+
+// LoadClassDefinitions reads the class definitions (and, if asked, their members) exactly
+// as the generator does and returns them in the order of their file names. The problems
+// it finds are reported as diagnostics (see Diagnostics). It fails if any are errors.
+func LoadClassDefinitions(opts ClassDefinitionOptions) (ret []Structure, err error) {
+	defer func() { err = wrapError("load", opts.RootPath, err) }()
+	defer recoverError(&err)
+
+	ResetDiagnostics()
+	structMap, err := loadClassDefinitions(opts)
 	if err != nil {
-		return cb, err
+		return nil, err
+	}
+	if err := diagnosticsError(); err != nil {
+		return nil, err
 	}
 
-	err = cb.FinishLoad(thePath, baseTypes, options, structMap)
-	if err != nil {
-		return cb, err
+	keys := make([]string, 0, len(structMap))
+	for key := range structMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		ret = append(ret, structMap[key])
 	}
+	return ret, nil
+}
 
-	return cb, nil
+// loadClassDefinitions returns the structures keyed by the lower case name of their file.
+func loadClassDefinitions(opts ClassDefinitionOptions) (map[string]Structure, error) {
+	classDefPath := opts.RootPath
+	if classDefPath == "" {
+		thePath, err := getTemplatePath()
+		if err != nil {
+			return nil, err
+		}
+		classDefPath = filepath.Join(thePath, "classDefinitions")
+	}
+	if !file.FolderExists(classDefPath) {
+		return nil, fmt.Errorf("classDefPath (%s) not found - quitting", classDefPath)
+	}
+	roots := []string{classDefPath}
+	if opts.RootPath == "" {
+		// The overlays may change or add class definitions
+		roots = templateFolders("classDefinitions")
+	}
+
+	var cb CodeBase
+	structMap := make(map[string]Structure)
+	if err := cb.loadStructures(roots, readStructure, structMap); err != nil {
+		return nil, err
+	}
+	if opts.LoadMembers {
+		if err := cb.loadMembers(roots, structMap); err != nil {
+			return nil, err
+		}
+		resolveMembers(roots, structMap)
+	} else {
+		for key, st := range structMap {
+			st.Members = nil
+			structMap[key] = st
+		}
+	}
+	if !opts.IncludeDisabled {
+		for key, st := range structMap {
+			if st.DisableGo {
+				delete(structMap, key)
+			}
+		}
+	}
+	if len(structMap) == 0 {
+		return nil, fmt.Errorf("no structures were loaded from %s - quitting", classDefPath)
+	}
+	return structMap, nil
 }
 
 func readStructure(st *Structure, data *any) (bool, error) {
@@ -85,30 +159,33 @@ func readStructure(st *Structure, data *any) (bool, error) {
 	st.DocGroup = strings.Trim(st.DocGroup, " ")
 	st.DocDescr = strings.Trim(st.DocDescr, " ")
 	st.DocNotes = strings.Trim(st.DocNotes, " ")
+	st.Extends = strings.Trim(st.Extends, " ")
 	return true, nil
 }
 
+// LoadStructures reads each .toml file under basePath, normalizes its settings with
+// callBack and its facets, and adds the result to structMap (if callBack returns true).
 func (cb *CodeBase) LoadStructures(basePath string, callBack func(*Structure, *any) (bool, error), structMap map[string]Structure) error {
-	if err := filepath.Walk(basePath, func(path string, info fs.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() || !strings.HasSuffix(path, ".toml") {
-			return nil
-		}
+	return cb.loadStructures([]string{basePath}, callBack, structMap)
+}
 
+// loadStructures is LoadStructures for the classDefinitions folders of several template
+// layers. A file in a later folder replaces the one with the same path in an earlier one.
+func (cb *CodeBase) loadStructures(roots []string, callBack func(*Structure, *any) (bool, error), structMap map[string]Structure) error {
+	for _, path := range layerFiles(roots, ".toml", mixinFolder) {
 		class := strings.TrimSuffix(filepath.Base(path), ".toml")
 		type Tmp struct {
 			Settings Structure `toml:"settings"` // don't change this, it won't parse
 			Facets   []Facet   `toml:"facets"`
+			Members  []Member  `toml:"members"`
 		}
 
 		var f Tmp
-		err = config.ReadToml(path, &f)
+		err := readClassDefinition(path, &f)
 		if err != nil {
 			return err
 		}
+		f.Settings.setPos(Position{File: path})
 		ok, err := callBack(&f.Settings, nil)
 		if err != nil {
 			return err
@@ -143,7 +220,29 @@ func (cb *CodeBase) LoadStructures(basePath string, callBack func(*Structure, *a
 				}
 				f.Facets[i].Name = strings.ReplaceAll(f.Facets[i].Name, " ", "")
 			}
-			f.Settings.Facets = f.Facets // Copy facets into the Structure			// If facetOrder is specified in TOML, reorder facets to match
+			f.Settings.Facets = f.Facets // Copy facets into the Structure
+
+			// Members may be defined inline rather than in fields/<class>.csv
+			if len(f.Members) > 0 {
+				lines := arrayTableLines(path, "members")
+				f.Settings.Members = make([]Member, 0, len(f.Members))
+				for i := range f.Members {
+					m := f.Members[i]
+					pos := Position{File: path, Key: fmt.Sprintf("members[%d]", i)}
+					if i < len(lines) {
+						pos.Line = lines[i]
+					}
+					m.setPos(pos)
+					_, _ = readMember(&m, nil)
+					if !m.Validate() {
+						reportError(pos, "member %d of structure %s has no name or type", i+1, f.Settings.Class)
+						continue
+					}
+					f.Settings.Members = append(f.Settings.Members, m)
+				}
+			}
+
+			// If facetOrder is specified in TOML, reorder facets to match
 			if len(f.Settings.FacetOrder) > 0 {
 				facetMap := make(map[string]Facet)
 				for _, facet := range f.Settings.Facets {
@@ -156,14 +255,14 @@ func (cb *CodeBase) LoadStructures(basePath string, callBack func(*Structure, *a
 						orderedFacets = append(orderedFacets, facet)
 						delete(facetMap, strings.ToLower(name))
 					} else {
-						logger.Warn("facetOrder references unknown facet:", name, "in structure:", f.Settings.Class)
+						reportWarning(f.Settings.pos.withKey("settings.facetOrder"), "facetOrder references unknown facet %s in structure %s", name, f.Settings.Class)
 					}
 				}
 
 				// Append any remaining facets not in facetOrder (for safety)
 				for _, facet := range f.Settings.Facets {
 					if _, stillExists := facetMap[strings.ToLower(facet.Name)]; stillExists {
-						logger.Warn("facet not in facetOrder, appending:", facet.Name, "in structure:", f.Settings.Class)
+						reportWarning(f.Settings.pos.withKey("settings.facetOrder"), "facet %s is not in facetOrder in structure %s, appending it", facet.Name, f.Settings.Class)
 						orderedFacets = append(orderedFacets, facet)
 					}
 				}
@@ -178,57 +277,77 @@ func (cb *CodeBase) LoadStructures(basePath string, callBack func(*Structure, *a
 
 			structMap[mapKey] = f.Settings
 		}
-		return nil
-	}); err != nil {
-		return err
 	}
 
 	return nil
 }
 
+// LoadMembers reads the members of the structures in structMap from the .csv files under
+// basePath. Structures whose members were given inline in their .toml file (see
+// LoadStructures) may not also have a .csv file.
 func (cb *CodeBase) LoadMembers(basePath string, structMap map[string]Structure) error {
-	if err := filepath.Walk(basePath, func(path string, info fs.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() || !strings.HasSuffix(path, ".csv") {
-			return nil
-		}
+	return cb.loadMembers([]string{basePath}, structMap)
+}
 
+// loadMembers is LoadMembers for the classDefinitions folders of several template layers.
+func (cb *CodeBase) loadMembers(roots []string, structMap map[string]Structure) error {
+	fromCsv := make(map[string]bool)
+	for _, path := range layerFiles(roots, ".csv", mixinFolder) {
 		class := strings.TrimSuffix(filepath.Base(path), ".csv")
 		mapKey := strings.ToLower(class)
 		structure := structMap[mapKey]
 		if structure.Class == "" {
-			return fmt.Errorf("structure %s not found at mapKey %s. Is there a TOML file?", class, mapKey)
+			reportError(Position{File: path}, "structure %s not found at mapKey %s. Is there a TOML file?", class, mapKey)
+			continue
+		}
+		if len(structure.Members) > 0 {
+			reportError(Position{File: path}, "the members of %s are defined in both %s and this file. Remove one of them.", structure.Class, cleanOutputPath(structure.pos.File))
+			continue
 		}
-		structure.Members, err = LoadCsv(path, readMember, nil)
+		members, err := LoadCsv(path, readMember, nil)
 		if err != nil {
 			return err
 		}
-		dupMap := make(map[string]bool, len(structure.Members))
-		for i := 0; i < len(structure.Members); i++ {
-			if dupMap[structure.Members[i].Name] {
-				return fmt.Errorf("duplicate member %s in class %s", structure.Members[i].Name, class)
-			}
-			structure.Members[i].Num = (i + 1)
-			dupMap[structure.Members[i].Name] = true
-		}
-		sort.Slice(structure.Members, func(i, j int) bool {
-			if structure.Members[i].DocOrder != 0 && (structure.Members[i].DocOrder != structure.Members[j].DocOrder) {
-				return structure.Members[i].DocOrder < structure.Members[j].DocOrder
-			}
-			return structure.Members[i].Num < structure.Members[j].Num
-		})
+		structure.Members = members
+		finishMembers(&structure, class)
 		structMap[mapKey] = structure
-		return nil
-	}); err != nil {
-		return err
+		fromCsv[mapKey] = true
+	}
+
+	for mapKey, structure := range structMap {
+		if !fromCsv[mapKey] && len(structure.Members) > 0 {
+			finishMembers(&structure, mapKey)
+			structMap[mapKey] = structure
+		}
 	}
 
 	return nil
 }
 
+// finishMembers numbers the structure's members in the order they were defined, reports
+// duplicates, and sorts them by docOrder.
+func finishMembers(structure *Structure, class string) {
+	dupMap := make(map[string]bool, len(structure.Members))
+	for i := 0; i < len(structure.Members); i++ {
+		if dupMap[structure.Members[i].Name] {
+			reportError(structure.Members[i].pos, "duplicate member %s in class %s", structure.Members[i].Name, class)
+		}
+		structure.Members[i].Num = (i + 1)
+		dupMap[structure.Members[i].Name] = true
+	}
+	sortMembers(structure.Members)
+}
+
+// sortMembers sorts members by docOrder and then by the order they were defined in.
+func sortMembers(members []Member) {
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].DocOrder != 0 && (members[i].DocOrder != members[j].DocOrder) {
+			return members[i].DocOrder < members[j].DocOrder
+		}
+		return members[i].Num < members[j].Num
+	})
+}
+
 func (cb *CodeBase) FinishLoad(unused string, baseTypes []Structure, options []Option, structMap map[string]Structure) error {
 	_ = unused
 	cb.BaseTypes = baseTypes
@@ -387,22 +506,30 @@ func (cb *CodeBase) FinishLoad(unused string, baseTypes []Structure, options []O
 		return cb.Commands[i].Route < cb.Commands[j].Route
 	})
 
-	if err := cb.Validate(); err != nil {
+	cb.nameEnums()
+
+	// Report every problem before giving up
+	_ = cb.Validate()
+	cb.checkReferences()
+	cb.checkHotKeys()
+	if err := diagnosticsError(); err != nil {
 		return err
 	}
 
-	for _, st := range cb.Structures {
-		st.checkHierarchy()
+	generatedPath := GetGeneratedPath()
+	codeBase := filepath.Join(generatedPath, "codebase.json")
+
+	// In dry-run mode, we leave the codebase snapshot alone but report on it
+	if dryRun {
+		recordChange(codeBase, file.AsciiFileToString(codeBase), cb.String(), !file.FileExists(codeBase))
+		return nil
 	}
 
-	// Get the path to the generated folder and ensure it exists
-	generatedPath := GetGeneratedPath()
+	// Ensure the generated folder exists
 	if err := file.EstablishFolder(generatedPath); err != nil {
 		return fmt.Errorf("failed to create generated directory: %w", err)
 	}
 
-	codeBase := filepath.Join(generatedPath, "codebase.json")
-
 	VerboseLog("Processing:", codeBase)
 	current := file.AsciiFileToString(codeBase)
 	_ = file.StringToAsciiFile(codeBase, cb.String())
@@ -411,145 +538,32 @@ func (cb *CodeBase) FinishLoad(unused string, baseTypes []Structure, options []O
 		return nil
 	}
 
-	if os.Getenv("TB_REMOTE_TESTING") == "true" {
+	if isRemoteTesting() {
 		return nil
 	}
 
 	return fmt.Errorf("quitting: %s has changed. Rerun the command to ignore this warning", codeBase)
 }
 
-func checkForDups(options []Option) error {
+func checkForDups(options []Option) {
 	dupMap := make(map[string]bool, len(options))
 	for _, op := range options {
 		key := op.Route + ":" + op.LongName
 		if len(key) > 1 && dupMap[key] {
-			return fmt.Errorf("duplicate option %s", key)
+			reportError(op.pos, "duplicate option %s", key)
 		}
 		dupMap[key] = true
 	}
-	return nil
-}
-
-func (s *Structure) checkHierarchy() {
-	if len(s.Parent) > 0 {
-		found := false
-		for _, st := range s.cbPtr.Structures {
-			if Lower(st.Name()) == s.Parent {
-				found = true
-				break
-			}
-		}
-		if !found {
-			logger.Error("parent", s.Parent, "of", s.Class, "not found")
-		}
-	}
-
-	for _, ch := range s.ChildTabs {
-		if ch != "" {
-			found := false
-			for _, st := range s.cbPtr.Structures {
-				if Plural(Lower(st.Name())) == ch {
-					found = true
-					break
-				}
-			}
-			if !found {
-				logger.Error("child", ch, "of", s.Class, "not found")
-			}
-		}
-	}
 }
 
 // ReadTomlFiles reads TOML files from ./code_gen/templates/classDefinitions
 // and returns a slice of Structure objects. If includeDisabled is false,
 // only returns structures where DisableGo is false.
+//
+// Deprecated: use LoadClassDefinitions.
 func ReadTomlFiles(includeDisabled bool) ([]Structure, error) {
-	classDefPath := "./code_gen/templates/classDefinitions"
-
-	if !file.FolderExists(classDefPath) {
-		return nil, fmt.Errorf("classDefinitions folder does not exist at %s", classDefPath)
-	}
-
-	var structures []Structure
-
-	err := filepath.Walk(classDefPath, func(path string, info fs.FileInfo, err error) error {
-		if err != nil {
-			return fmt.Errorf("error walking path %s: %w", path, err)
-		}
-
-		if info.IsDir() || !strings.HasSuffix(path, ".toml") {
-			return nil
-		}
-
-		type Tmp struct {
-			Settings Structure `toml:"settings"`
-			Facets   []Facet   `toml:"facets"`
-		}
-
-		var f Tmp
-		err = config.ReadToml(path, &f)
-		if err != nil {
-			return fmt.Errorf("failed to parse TOML file %s: %w", path, err)
-		}
-
-		// Apply the same processing as LoadStructures
-		f.Settings.Class = strings.ReplaceAll(f.Settings.Class, " ", "")
-		f.Settings.DocDescr = strings.ReplaceAll(f.Settings.DocDescr, "&#44;", ",")
-		f.Settings.ProducedBy = strings.ReplaceAll(f.Settings.ProducedBy, " ", "")
-		f.Settings.Producers = strings.Split(f.Settings.ProducedBy, ",")
-		f.Settings.ChildTabs = strings.Split(f.Settings.Children, ",")
-		f.Settings.Class = strings.Trim(f.Settings.Class, " ")
-		f.Settings.DocGroup = strings.Trim(f.Settings.DocGroup, " ")
-		f.Settings.DocDescr = strings.Trim(f.Settings.DocDescr, " ")
-		f.Settings.DocNotes = strings.Trim(f.Settings.DocNotes, " ")
-
-		if len(f.Settings.DisplayName) == 0 {
-			f.Settings.DisplayName = f.Settings.Class
-		}
-
-		// Process facets
-		for i := range f.Facets {
-			f.Facets[i].NormalizeActions()
-		}
-		for i := range f.Facets {
-			if strings.Contains(f.Facets[i].Store, ".") {
-				parts := strings.Split(f.Facets[i].Store, ".")
-				if len(parts) >= 2 {
-					f.Facets[i].StoreSource = parts[0]
-					f.Facets[i].StoreName = parts[1]
-				}
-			} else {
-				f.Facets[i].StoreSource = "sdk"
-				f.Facets[i].StoreName = f.Facets[i].Store
-			}
-			f.Facets[i].Store = "NOT NEEDED AFTER LOAD - THIS MESSAGE PROVES IT"
-		}
-		for i := 0; i < len(f.Facets); i++ {
-			// Use Label if provided, otherwise use DisplayName if set, otherwise fall back to Name
-			if len(f.Facets[i].Label) > 0 {
-				f.Facets[i].DisplayName = f.Facets[i].Label
-			} else if len(f.Facets[i].DisplayName) == 0 {
-				f.Facets[i].DisplayName = f.Facets[i].Name
-			}
-			f.Facets[i].Name = strings.ReplaceAll(f.Facets[i].Name, " ", "")
-		}
-		f.Settings.Facets = f.Facets
-
-		// Filter based on includeDisabled parameter
-		if !includeDisabled && f.Settings.DisableGo {
-			return nil // Skip disabled structures
-		}
-
-		structures = append(structures, f.Settings)
-		return nil
+	return LoadClassDefinitions(ClassDefinitionOptions{
+		RootPath:        "./code_gen/templates/classDefinitions",
+		IncludeDisabled: includeDisabled,
 	})
-	if err != nil {
-		return nil, err
-	}
-
-	if len(structures) == 0 {
-		return nil, fmt.Errorf("no enabled TOML structures found in %s", classDefPath)
-	}
-
-	return structures, nil
 }
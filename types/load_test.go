@@ -0,0 +1,125 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadClassDefinitions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"block.toml": `[settings]
+class = "Block"
+facetOrder = ["Logs", "Receipts"]
+
+[[facets]]
+name = "Receipts"
+store = "Receipts"
+
+[[facets]]
+name = "Logs"
+store = "app.Logs"
+`,
+		"trace.toml":       "[settings]\nclass = \"Trace\"\ndisable_go = true\n",
+		"fields/block.csv": "name,type,strDefault,attributes,docOrder,description\nhash,hash,,,1,the hash\n",
+		"fields/trace.csv": "name,type,strDefault,attributes,docOrder,description\nerror,string,,,1,the error\n",
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	structures, err := LoadClassDefinitions(ClassDefinitionOptions{RootPath: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(structures) != 1 || structures[0].Class != "Block" {
+		t.Fatalf("expected only Block, got %d structures", len(structures))
+	}
+	st := structures[0]
+	if st.MenuPosition != "top" || len(st.Members) != 0 {
+		t.Errorf("unexpected defaults: menuPosition %q, %d members", st.MenuPosition, len(st.Members))
+	}
+	if len(st.Facets) != 2 || st.Facets[0].Name != "Logs" || st.Facets[0].StoreSource != "app" || st.Facets[1].StoreSource != "sdk" {
+		t.Errorf("facets not normalized: %+v", st.Facets)
+	}
+
+	structures, err = LoadClassDefinitions(ClassDefinitionOptions{RootPath: dir, IncludeDisabled: true, LoadMembers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(structures) != 2 || len(structures[0].Members) != 1 || structures[1].Members[0].Name != "error" {
+		t.Errorf("expected both structures with their members, got %+v", structures)
+	}
+}
+
+func TestLoadInlineMembers(t *testing.T) {
+	ResetDiagnostics()
+	defer ResetDiagnostics()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"log.toml": `[settings]
+class = "Log"
+
+[[members]]
+name = "topics"
+type = "[]hash"
+docOrder = 2
+description = """
+the topics \
+of the log"""
+
+[[members]]
+name = "address"
+type = "address"
+docOrder = 1
+description = "the address, which emitted the log"
+`,
+		"trace.toml": "[settings]\nclass = \"Trace\"\n\n[[members]]\nname = \"error\"\ntype = \"string\"\n",
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	structures, err := LoadClassDefinitions(ClassDefinitionOptions{RootPath: dir, LoadMembers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	members := structures[0].Members
+	if len(members) != 2 || members[0].Name != "address" || members[0].Num != 2 || members[1].Description != "the topics of the log" || !members[1].IsArray {
+		t.Errorf("unexpected members %+v", members)
+	}
+	if members[0].pos.Line != 12 {
+		t.Errorf("expected address on line 12, got %d", members[0].pos.Line)
+	}
+
+	// A structure's members may not be defined in both places
+	csv := "name,type,strDefault,attributes,docOrder,description\nerror,string,,,1,the error\n"
+	if err := os.MkdirAll(filepath.Join(dir, "fields"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "fields", "trace.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadClassDefinitions(ClassDefinitionOptions{RootPath: dir, LoadMembers: true}); err == nil {
+		t.Fatal("expected an error")
+	}
+	diags := Diagnostics()
+	if len(diags) != 1 || !strings.Contains(diags[0].Message, "defined in both") {
+		t.Errorf("expected an error for members in both files, got %v", diags)
+	}
+}
@@ -0,0 +1,189 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
+)
+
+// ManifestEntry describes a single file written by goMaker.
+type ManifestEntry struct {
+	Path     string `json:"path"`
+	Template string `json:"template"`
+	Receiver string `json:"receiver"`
+	Hash     string `json:"hash"`
+}
+
+// Manifest lists every file produced by the most recent run of goMaker.
+type Manifest struct {
+	Version string          `json:"version"`
+	Files   []ManifestEntry `json:"files"`
+}
+
+var (
+	produced      = map[string]ManifestEntry{}
+	producedMutex sync.Mutex
+)
+
+func getManifestPath() string {
+	return filepath.Join(GetGeneratedPath(), "manifest.json")
+}
+
+func resetProduced() {
+	producedMutex.Lock()
+	defer producedMutex.Unlock()
+	produced = map[string]ManifestEntry{}
+}
+
+// markProduced notes that this run produced dest (whether it was written or was
+// already up to date).
+func markProduced(dest string, deps Dependency) {
+	if deps.Output == "" {
+		return // nothing was written (the template produced no code)
+	}
+	path := cleanOutputPath(dest)
+	producedMutex.Lock()
+	defer producedMutex.Unlock()
+	produced[path] = ManifestEntry{
+		Path:     path,
+		Template: deps.Template,
+		Receiver: deps.Receiver,
+		Hash:     deps.Output,
+	}
+}
+
+// LoadManifest returns the manifest written by the most recent run. If there is no
+// manifest, it returns an empty one.
+func LoadManifest() (Manifest, error) {
+	var manifest Manifest
+	path := getManifestPath()
+	if !file.FileExists(path) {
+		return manifest, nil
+	}
+	if err := json.Unmarshal([]byte(file.AsciiFileToString(path)), &manifest); err != nil {
+		return manifest, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+func saveManifest(manifest Manifest) error {
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Path < manifest.Files[j].Path
+	})
+	bytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return file.StringToAsciiFile(getManifestPath(), string(bytes)+"\n")
+}
+
+// writeManifest records the files produced by this run. If the run was complete (that is,
+// not limited by targets or filters), the manifest lists exactly those files and entries
+// for files no longer produced are dropped from the dependency graph. Otherwise, the new
+// entries are merged into the previous manifest.
+func writeManifest(complete bool) error {
+	producedMutex.Lock()
+	defer producedMutex.Unlock()
+
+	manifest := Manifest{Version: toolVersion}
+	if !complete {
+		previous, err := LoadManifest()
+		if err != nil {
+			return err
+		}
+		for _, entry := range previous.Files {
+			if _, ok := produced[entry.Path]; !ok {
+				manifest.Files = append(manifest.Files, entry)
+			}
+		}
+	} else {
+		graphMutex.Lock()
+		for path := range graph.Outputs {
+			if _, ok := produced[path]; !ok {
+				delete(graph.Outputs, path)
+			}
+		}
+		graphMutex.Unlock()
+	}
+
+	for _, entry := range produced {
+		manifest.Files = append(manifest.Files, entry)
+	}
+	return saveManifest(manifest)
+}
+
+// isCompleteRun returns true if nothing limits which outputs a run produces. A run with
+// some of the generator categories turned off in gomaker.toml is not complete.
+func isCompleteRun(targets *Targets) bool {
+	return targets == nil && getSingle() == "" && getFilter() == "" && allCategoriesEnabled()
+}
+
+// IsModified returns true if the file on disk is no longer the one goMaker wrote.
+func (e *ManifestEntry) IsModified() bool {
+	path := inOutputRoot(e.Path)
+	return file.FileExists(path) && hashFile(path, false) != e.Hash
+}
+
+// Orphans returns the files listed in the manifest that the generators no longer produce
+// (for example, because a route was removed or a type's output path changed).
+func (cb *CodeBase) Orphans() ([]ManifestEntry, error) {
+	if getSingle() != "" || getFilter() != "" {
+		return nil, fmt.Errorf("finding orphans requires every generator; unset TB_MAKER_SINGLE and TB_GENERATOR_FILTER")
+	}
+	if !allCategoriesEnabled() {
+		return nil, fmt.Errorf("finding orphans requires every generator; enable every category in %s", ProjectConfigFile)
+	}
+
+	manifest, err := LoadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := cb.Outputs()
+	if err != nil {
+		return nil, err
+	}
+	current := map[string]bool{}
+	for _, o := range outputs {
+		current[cleanOutputPath(o.Path)] = true
+	}
+
+	ret := []ManifestEntry{}
+	for _, entry := range manifest.Files {
+		if !current[entry.Path] {
+			ret = append(ret, entry)
+		}
+	}
+	return ret, nil
+}
+
+// RemoveOrphans deletes the given files from disk and drops them from the manifest. The
+// paths in the manifest are relative to the output root.
+func RemoveOrphans(orphans []ManifestEntry) error {
+	manifest, err := LoadManifest()
+	if err != nil {
+		return err
+	}
+
+	removed := map[string]bool{}
+	for _, orphan := range orphans {
+		if err := os.Remove(inOutputRoot(orphan.Path)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		removed[orphan.Path] = true
+	}
+
+	files := []ManifestEntry{}
+	for _, entry := range manifest.Files {
+		if !removed[entry.Path] {
+			files = append(files, entry)
+		}
+	}
+	manifest.Files = files
+	return saveManifest(manifest)
+}
@@ -0,0 +1,184 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
+)
+
+// generateCopy generates everything from a copy of the repository's templates folder into
+// a temporary output root and returns the codebase and the output root.
+func generateCopy(t *testing.T) (CodeBase, string) {
+	t.Helper()
+	cb, templates := loadTemplatesCopy(t)
+	root := filepath.Dir(templates)
+	if err := os.MkdirAll(filepath.Join(root, "generated"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// The repository's templates do not have an intro for every model
+	for _, s := range cb.Structures {
+		if intro := filepath.Join(templates, "model-intros", CamelCase(s.Class)+".md"); !file.FileExists(intro) {
+			writeFile(t, intro, s.Class+"\n")
+		}
+	}
+	SetDryRun(false)
+	savedGraph := graph
+	t.Cleanup(func() {
+		graph = savedGraph
+		resetProduced()
+	})
+
+	if err := cb.GenerateOnly(nil); err != nil {
+		t.Fatal(err)
+	}
+	return cb, root
+}
+
+func manifestPaths(t *testing.T) map[string]bool {
+	t.Helper()
+	manifest, err := LoadManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ret := map[string]bool{}
+	for _, entry := range manifest.Files {
+		ret[entry.Path] = true
+	}
+	return ret
+}
+
+func TestPruneKeepsCurrentFiles(t *testing.T) {
+	cb, root := generateCopy(t)
+
+	current := manifestPaths(t)
+	if len(current) == 0 {
+		t.Fatal("the manifest is empty")
+	}
+	for path := range current {
+		if filepath.IsAbs(path) || !file.FileExists(filepath.Join(root, path)) {
+			t.Fatalf("the manifest lists %s, which is not a generated file in the output root", path)
+		}
+	}
+	if orphans, err := cb.Orphans(); err != nil || len(orphans) != 0 {
+		t.Fatalf("expected no orphans right after generating, got %v, %v", orphans, err)
+	}
+
+	// A file an earlier run produced that is no longer generated
+	manifest, _ := LoadManifest()
+	stale := ManifestEntry{Path: "src/old/removed.go", Template: "old.go.tmpl", Hash: "x"}
+	writeFile(t, filepath.Join(root, stale.Path), "old\n")
+	manifest.Files = append(manifest.Files, stale)
+	if err := saveManifest(manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	orphans, err := cb.Orphans()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphans) != 1 || orphans[0].Path != stale.Path {
+		t.Fatalf("expected only %s to be an orphan, got %v", stale.Path, orphans)
+	}
+	if !orphans[0].IsModified() {
+		t.Error("the stale file does not match its hash, so it should be reported as modified")
+	}
+	if err := RemoveOrphans(orphans); err != nil {
+		t.Fatal(err)
+	}
+
+	if file.FileExists(filepath.Join(root, stale.Path)) {
+		t.Error("the orphan was not removed")
+	}
+	after := manifestPaths(t)
+	if after[stale.Path] {
+		t.Error("the orphan is still in the manifest")
+	}
+	for path := range current {
+		if !after[path] {
+			t.Errorf("%s was dropped from the manifest", path)
+		}
+		if !file.FileExists(filepath.Join(root, path)) {
+			t.Errorf("%s was removed from disk", path)
+		}
+	}
+}
+
+func TestPartialRunKeepsManifest(t *testing.T) {
+	cb, _ := generateCopy(t)
+	complete := manifestPaths(t)
+
+	// A run limited to one route produces only that route's outputs
+	if isCompleteRun(&Targets{Routes: map[string]bool{"blocks": true}}) {
+		t.Error("a run with targets is not complete")
+	}
+	if err := cb.GenerateOnly(&Targets{Routes: map[string]bool{"blocks": true}}); err != nil {
+		t.Fatal(err)
+	}
+	partial := manifestPaths(t)
+	if len(partial) != len(complete) {
+		t.Errorf("a partial run changed the manifest from %d to %d files", len(complete), len(partial))
+	}
+	for path := range complete {
+		if !partial[path] {
+			t.Errorf("a partial run dropped %s from the manifest", path)
+		}
+	}
+	if orphans, err := cb.Orphans(); err != nil || len(orphans) != 0 {
+		t.Errorf("a partial run should not create orphans, got %v, %v", orphans, err)
+	}
+
+	// So does a filtered run, and orphans cannot be found while filtering
+	SetFilter("routes")
+	defer SetFilter("")
+	if isCompleteRun(nil) {
+		t.Error("a filtered run is not complete")
+	}
+	if err := cb.GenerateOnly(nil); err != nil {
+		t.Fatal(err)
+	}
+	if filtered := manifestPaths(t); len(filtered) != len(complete) {
+		t.Errorf("a filtered run changed the manifest from %d to %d files", len(complete), len(filtered))
+	}
+	if _, err := cb.Orphans(); err == nil {
+		t.Error("expected an error finding orphans while filtering")
+	}
+}
+
+func TestDisabledCategoriesKeepManifest(t *testing.T) {
+	cb, root := generateCopy(t)
+	complete := manifestPaths(t)
+
+	config := filepath.Join(root, ProjectConfigFile)
+	writeFile(t, config, "[profiles.models.generators]\nenabled = [\"types\"]\n")
+	cfg, err := LoadProjectConfig(config, "models")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetProjectConfig(cfg)
+	defer SetProjectConfig(nil)
+
+	if isCompleteRun(nil) {
+		t.Error("a run with only some categories enabled is not complete")
+	}
+	if err := cb.GenerateOnly(nil); err != nil {
+		t.Fatal(err)
+	}
+	after := manifestPaths(t)
+	for path := range complete {
+		if !after[path] {
+			t.Errorf("a run with only types enabled dropped %s from the manifest", path)
+		}
+	}
+
+	// The routes and codebase outputs are not reported (let alone removed) as orphans
+	if orphans, err := cb.Orphans(); err == nil {
+		t.Errorf("expected an error finding orphans with only types enabled, got %v", orphans)
+	}
+	for path := range complete {
+		if !file.FileExists(filepath.Join(root, path)) {
+			t.Errorf("%s was removed", path)
+		}
+	}
+}
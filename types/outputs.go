@@ -0,0 +1,193 @@
+package types
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
+)
+
+// Output describes a single file produced by applying a generator template to a receiver.
+type Output struct {
+	Template string   `json:"template"`
+	Scope    string   `json:"scope"`
+	Route    string   `json:"route,omitempty"`
+	Type     string   `json:"type,omitempty"`
+	Group    string   `json:"group,omitempty"`
+	Reason   string   `json:"reason,omitempty"`
+	Facet    string   `json:"facet,omitempty"`
+	Path     string   `json:"path"`
+	Inputs   []string `json:"inputs,omitempty"`
+	// Layers maps the template and each input to the layer (base or an overlay) that
+	// supplied it. It is set only when there are template overlays.
+	Layers map[string]string `json:"layers,omitempty"`
+}
+
+// Layer returns the layer that supplied the template or input, if there are overlays.
+func (o *Output) Layer(path string) string {
+	return o.Layers[path]
+}
+
+// Receiver returns a short description of the item the template was applied to.
+func (o *Output) Receiver() string {
+	switch o.Scope {
+	case "codebase":
+		return "codebase"
+	case "groups":
+		return "group " + o.Group + " (" + o.Reason + ")"
+	case "routes":
+		return "route " + o.Route
+	case "types":
+		if o.Facet != "" {
+			return "facet " + o.Type + "." + o.Facet
+		}
+		return "type " + o.Type
+	}
+	return o.Scope
+}
+
+// Outputs returns every file the generators would produce for this codebase without
+// rendering any of them. The order matches the order used by Generate.
+func (cb *CodeBase) Outputs() (ret []Output, err error) {
+	defer recoverError(&err)
+
+	generators, err := getGenerators()
+	if err != nil {
+		return nil, err
+	}
+
+	ret = []Output{}
+	for _, generator := range generators {
+		for _, source := range generator.Templates {
+			fullPath := getGeneratorPath(generator.Against, source)
+			switch generator.Against {
+			case "codebase":
+				if ok, err := shouldProcess(fullPath, generator.Against, "codebase"); err != nil {
+					return nil, err
+				} else if ok {
+					o := Output{Template: fullPath, Scope: generator.Against}
+					ret = append(ret, o.withPath("", "", "", ""))
+				}
+			case "groups":
+				// handled below so the order matches Generate
+			case "routes":
+				for _, c := range cb.Commands {
+					if ok, err := shouldProcess(fullPath, generator.Against, c.Route); err != nil {
+						return nil, err
+					} else if ok {
+						o := Output{Template: fullPath, Scope: generator.Against, Route: c.Route}
+						ret = append(ret, o.withPath("", c.Route, "", ""))
+					}
+				}
+			case "types":
+				for _, s := range cb.Structures {
+					if s.DisableGo {
+						continue
+					}
+					if ok, err := shouldProcess(fullPath, generator.Against, s.Class); err != nil {
+						return nil, err
+					} else if !ok {
+						continue
+					}
+					route := s.Route
+					if route == "" {
+						route = strings.ToLower(s.Class)
+					}
+					o := Output{Template: fullPath, Scope: generator.Against, Type: s.Name()}
+					o = o.withPath("", route, s.Name(), "")
+					if strings.Contains(o.Path, "/-facet-") {
+						dest := o.Path
+						for _, facet := range s.Facets {
+							name := Lower("/" + facet.Name)
+							if name == "/index" {
+								name = "/indexdata"
+							}
+							oo := o
+							oo.Facet = facet.Name
+							oo.Path = strings.ReplaceAll(dest, "/-facet-", name)
+							oo.Path = strings.ReplaceAll(oo.Path, "/-Facet-", "/"+facet.Name)
+							ret = append(ret, oo)
+						}
+					} else {
+						ret = append(ret, o)
+					}
+				}
+			default:
+				return nil, fmt.Errorf("unknown against value: %s", generator.Against)
+			}
+		}
+
+		if generator.Against == "groups" {
+			for _, reason := range []string{"readme", "model"} {
+				for _, source := range generator.Templates {
+					fullPath := getGeneratorPath(generator.Against, source)
+					for _, group := range cb.GroupList("") {
+						if ok, err := shouldProcess(fullPath, generator.Against, "codebase"); err != nil {
+							return nil, err
+						} else if ok {
+							o := Output{Template: fullPath, Scope: generator.Against, Group: group.GroupName(), Reason: reason}
+							ret = append(ret, o.withPath(reason, "", "", group.GroupName()))
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+func (o Output) withPath(reason, routeTag, typeTag, groupTag string) Output {
+	if metadata := parseMetadataBlock(file.AsciiFileToString(o.Template), reason); metadata != nil {
+		metadata.Route = routeTag
+		metadata.Type = typeTag
+		metadata.Group = groupTag
+		metadata.Reason = reason
+		o.Path = metadata.processPath()
+	}
+	return o
+}
+
+// Explain returns the outputs (there may be more than one) that write to the given path
+// along with the intro, notes, and partial files each one reads.
+func (cb *CodeBase) Explain(path string) (ret []Output, err error) {
+	defer recoverError(&err)
+
+	outputs, err := cb.Outputs()
+	if err != nil {
+		return nil, err
+	}
+
+	target := cleanOutputPath(path)
+	ret = []Output{}
+	for _, o := range outputs {
+		if cleanOutputPath(o.Path) == target {
+			o.Inputs = cb.inputs(o)
+			if hasOverlays() {
+				o.Layers = map[string]string{o.Template: layerOf(o.Template)}
+				for _, input := range o.Inputs {
+					if layer := layerOf(input); layer != "" {
+						o.Layers[input] = layer
+					}
+				}
+			}
+			ret = append(ret, o)
+		}
+	}
+	return ret, nil
+}
+
+func cleanOutputPath(path string) string {
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(getOutputRoot(), path); err == nil {
+			path = rel
+		}
+	}
+	return filepath.Clean(path)
+}
+
+// RelativePath returns the path relative to the output root (if it is inside of it).
+func RelativePath(path string) string {
+	return cleanOutputPath(path)
+}
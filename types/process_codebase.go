@@ -1,16 +1,12 @@
 package types
 
-import (
-	"os"
-	"path/filepath"
-)
-
 // ProcessFile processes a single file, applying the template to it and
 // writing the result to the destination.
-func (item *CodeBase) ProcessFile(source, group, reason string) error {
-	cwd, _ := os.Getwd()
+func (item *CodeBase) ProcessFile(source, group, reason string) (err error) {
+	defer recoverError(&err)
+
 	subPath := "codebase"
-	fullPath := filepath.Join(cwd, getGeneratorsPath(), subPath, source)
+	fullPath := getGeneratorPath(subPath, source)
 	if ok, err := shouldProcess(fullPath, subPath, "codebase"); err != nil {
 		return err
 	} else if !ok {
@@ -18,9 +14,16 @@ func (item *CodeBase) ProcessFile(source, group, reason string) error {
 	}
 
 	tmpl, dest := getGeneratorContentsAndDest(fullPath, subPath, group, reason, "", "", group)
+	deps := dependencies(fullPath, subPath, "codebase", receiverHash("codebase"))
+	if upToDate(dest, deps) {
+		return nil
+	}
+
 	tmplName := fullPath + group + reason
 	result := item.executeTemplate(tmplName, tmpl)
-	_, err := WriteCode(dest, result)
+	if _, err = WriteCode(dest, result); err == nil {
+		recordDependency(dest, deps)
+	}
 
 	return err
 }
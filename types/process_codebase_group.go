@@ -1,18 +1,14 @@
 package types
 
-import (
-	"os"
-	"path/filepath"
-)
-
 // ProcessGroupFile processes a single file, applying the template to it and
 // writing the result to the destination.
-func (item *CodeBase) ProcessGroupFile(source, group, reason string) error {
+func (item *CodeBase) ProcessGroupFile(source, group, reason string) (err error) {
+	defer recoverError(&err)
+
 	VerboseLog("Processing group file:", source, "for group:", group, "reason:", reason)
 
-	cwd, _ := os.Getwd()
 	subPath := "groups"
-	fullPath := filepath.Join(cwd, getGeneratorsPath(), subPath, source)
+	fullPath := getGeneratorPath(subPath, source)
 	if ok, err := shouldProcess(fullPath, subPath, "codebase"); err != nil {
 		return err
 	} else if !ok {
@@ -22,10 +18,18 @@ func (item *CodeBase) ProcessGroupFile(source, group, reason string) error {
 
 	VerboseLog("  Reading template from:", fullPath)
 	tmpl, dest := getGeneratorContentsAndDest(fullPath, subPath, group, reason, "", "", group)
+	deps := dependencies(fullPath, subPath, group+"/"+reason, receiverHash(group+"/"+reason))
+	if upToDate(dest, deps) {
+		VerboseLog("  Skipping unchanged file:", dest)
+		return nil
+	}
+
 	VerboseLog("  Generating file:", dest)
 	tmplName := fullPath + group + reason
 	result := item.executeTemplate(tmplName, tmpl)
-	_, err := WriteCode(dest, result)
+	if _, err = WriteCode(dest, result); err == nil {
+		recordDependency(dest, deps)
+	}
 
 	return err
 }
@@ -1,16 +1,12 @@
 package types
 
-import (
-	"os"
-	"path/filepath"
-)
-
 // ProcessFile processes a single file, applying the template to it and
 // writing the result to the destination.
-func (item *Command) ProcessFile(source, group, reason string) error {
-	cwd, _ := os.Getwd()
+func (item *Command) ProcessFile(source, group, reason string) (err error) {
+	defer recoverError(&err)
+
 	subPath := "routes"
-	fullPath := filepath.Join(cwd, getGeneratorsPath(), subPath, source)
+	fullPath := getGeneratorPath(subPath, source)
 	if ok, err := shouldProcess(fullPath, subPath, item.Route); err != nil {
 		return err
 	} else if !ok {
@@ -18,9 +14,16 @@ func (item *Command) ProcessFile(source, group, reason string) error {
 	}
 
 	tmpl, dest := getGeneratorContentsAndDest(fullPath, subPath, group, reason, item.Route, "", group)
+	deps := dependencies(fullPath, subPath, item.Route, commandHash(item))
+	if upToDate(dest, deps) {
+		return nil
+	}
+
 	tmplName := fullPath + group + reason
 	result := item.executeTemplate(tmplName, tmpl)
-	_, err := WriteCode(dest, result)
+	if _, err = WriteCode(dest, result); err == nil {
+		recordDependency(dest, deps)
+	}
 
 	return err
 }
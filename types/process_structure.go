@@ -1,19 +1,18 @@
 package types
 
 import (
-	"os"
-	"path/filepath"
 	"strings"
 )
 
 // ProcessFile processes a single file, applying the template to it and
 // writing the result to the destination.
-func (item *Structure) ProcessFile(sourceIn, group, reason string) error {
+func (item *Structure) ProcessFile(sourceIn, group, reason string) (err error) {
+	defer recoverError(&err)
+
 	VerboseLog("Processing structure file:", sourceIn, "for type:", item.Name())
 
-	cwd, _ := os.Getwd()
 	subPath := "types"
-	fullPath := filepath.Join(cwd, getGeneratorsPath(), subPath, sourceIn)
+	fullPath := getGeneratorPath(subPath, sourceIn)
 	if ok, err := shouldProcess(fullPath, subPath, item.Class); err != nil {
 		return err
 	} else if !ok {
@@ -28,6 +27,7 @@ func (item *Structure) ProcessFile(sourceIn, group, reason string) error {
 		route = strings.ToLower(item.Class)
 	}
 	tmpl, dest := getGeneratorContentsAndDest(fullPath, subPath, group, reason, route, item.Name(), group)
+	deps := dependencies(fullPath, subPath, item.Class, structureHash(item))
 	if strings.Contains(dest, "/-facet-") {
 		for _, facet := range item.Facets {
 			name := Lower("/" + facet.Name)
@@ -36,6 +36,9 @@ func (item *Structure) ProcessFile(sourceIn, group, reason string) error {
 			}
 			dd := strings.ReplaceAll(dest, "/-facet-", name)
 			dd = strings.ReplaceAll(dd, "/-Facet-", "/"+facet.Name)
+			if upToDate(dd, deps) {
+				continue
+			}
 			VerboseLog("  Generating file:", dd)
 			tmplName := fullPath + group + reason + facet.Name
 			result := facet.executeTemplate(tmplName, tmpl)
@@ -43,18 +46,23 @@ func (item *Structure) ProcessFile(sourceIn, group, reason string) error {
 			if strings.Contains(dest, "Panel") && strings.Contains(tmpl, "onFinal") && !strings.Contains(name, "openapprovals") {
 				result = strings.ReplaceAll(result, "onFinal", "_onFinal")
 			}
-			_, err := WriteCode(dd, result)
-			if err != nil {
+			if _, err := WriteCode(dd, result); err != nil {
 				return err
 			}
+			recordDependency(dd, deps)
 		}
 	} else {
+		if upToDate(dest, deps) {
+			VerboseLog("  Skipping unchanged file:", dest)
+			return nil
+		}
 		VerboseLog("  Generating file:", dest)
 		tmplName := fullPath + group + reason
 		result := item.executeTemplate(tmplName, tmpl)
-		_, err := WriteCode(dest, result)
-
-		return err
+		if _, err := WriteCode(dest, result); err != nil {
+			return err
+		}
+		recordDependency(dest, deps)
 	}
 
 	return nil
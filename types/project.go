@@ -0,0 +1,209 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// ProjectConfigFile is the name of the project configuration file. It is read from the
+// output root (by default, the current working directory).
+const ProjectConfigFile = "gomaker.toml"
+
+// ProjectConfig is the contents of gomaker.toml. Relative paths are relative to the folder
+// holding the file. A named profile overrides any of the settings it contains.
+type ProjectConfig struct {
+	DefaultProfile string                   `toml:"default_profile"`
+	Paths          ProjectPaths             `toml:"paths"`
+	Formatter      FormatterConfig          `toml:"formatter"`
+	Generators     GeneratorsConfig         `toml:"generators"`
+	Profiles       map[string]ProjectConfig `toml:"profiles"`
+	path           string
+	profile        string
+}
+
+// ProjectPaths locates the inputs and outputs of the generator.
+type ProjectPaths struct {
+	Templates  string   `toml:"templates"`  // the templates folder
+	Overlays   []string `toml:"overlays"`   // folders layered, in order, over the templates folder
+	Generators string   `toml:"generators"` // the generators folder
+	Output     string   `toml:"output"`     // the folder generated paths are relative to
+	Help       string   `toml:"help"`       // the frontend's help files (frontend/src/assets/help)
+	Validators string   `toml:"validators"` // each route's validate.go (chifra/internal/[[route]]/validate.go)
+}
+
+// FormatterConfig controls how generated code is formatted.
+type FormatterConfig struct {
+	Gofmt          *bool  `toml:"gofmt"`           // format Go code (default true)
+	Prettier       *bool  `toml:"prettier"`        // format yaml, jsx, and tsx with prettier if found (default true)
+	PrettierPath   string `toml:"prettier_path"`   // the prettier to use instead of searching for one
+	PrettierConfig string `toml:"prettier_config"` // the prettier configuration file to use
+}
+
+// GeneratorsConfig chooses which generators run.
+type GeneratorsConfig struct {
+	// Enabled lists the generator categories to run (codebase, groups, routes, or
+	// types). If empty, all of them run.
+	Enabled []string `toml:"enabled"`
+}
+
+var generatorCategories = []string{"codebase", "groups", "routes", "types"}
+
+// project is the configuration in use (nil if there is none).
+var project *ProjectConfig
+
+// Path returns the file the configuration was read from.
+func (p *ProjectConfig) Path() string {
+	return p.path
+}
+
+// Profile returns the name of the profile applied to the configuration (if any).
+func (p *ProjectConfig) Profile() string {
+	return p.profile
+}
+
+// LoadProjectConfig reads the configuration at path and applies the named profile. If
+// profile is empty, TB_MAKER_PROFILE or the file's default_profile is used.
+func LoadProjectConfig(path, profile string) (*ProjectConfig, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg ProjectConfig
+	decoder := toml.NewDecoder(bytes.NewReader(contents))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		var strict *toml.StrictMissingError
+		if errors.As(err, &strict) && len(strict.Errors) > 0 {
+			row, _ := strict.Errors[0].Position()
+			return nil, fmt.Errorf("line %d: unknown key %s", row, strings.Join(strict.Errors[0].Key(), "."))
+		}
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			row, _ := decodeErr.Position()
+			return nil, fmt.Errorf("line %d: %w", row, err)
+		}
+		return nil, err
+	}
+
+	if profile == "" {
+		profile = os.Getenv("TB_MAKER_PROFILE")
+	}
+	if profile == "" {
+		profile = cfg.DefaultProfile
+	}
+	for name, p := range cfg.Profiles {
+		if p.DefaultProfile != "" || len(p.Profiles) > 0 {
+			return nil, fmt.Errorf("profile %s may not contain default_profile or profiles", name)
+		}
+	}
+
+	ret := cfg
+	if profile != "" {
+		p, ok := cfg.Profiles[profile]
+		if !ok {
+			names := []string{}
+			for name := range cfg.Profiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if len(names) == 0 {
+				return nil, fmt.Errorf("unknown profile %s (the file has no profiles)", profile)
+			}
+			return nil, fmt.Errorf("unknown profile %s (expected one of %s)", profile, strings.Join(names, ", "))
+		}
+		ret.merge(&p)
+	}
+	ret.Profiles = nil
+	ret.profile = profile
+
+	for _, category := range ret.Generators.Enabled {
+		if !slices.Contains(generatorCategories, category) {
+			return nil, fmt.Errorf("unknown generator category %s (expected one of %s)", category, strings.Join(generatorCategories, ", "))
+		}
+	}
+
+	if ret.path, err = filepath.Abs(path); err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(ret.path)
+	resolve := func(p *string) {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(dir, *p)
+		}
+	}
+	resolve(&ret.Paths.Templates)
+	for i := range ret.Paths.Overlays {
+		resolve(&ret.Paths.Overlays[i])
+	}
+	resolve(&ret.Paths.Generators)
+	resolve(&ret.Paths.Output)
+	resolve(&ret.Paths.Help)
+	resolve(&ret.Paths.Validators)
+	resolve(&ret.Formatter.PrettierConfig)
+	if strings.Contains(ret.Formatter.PrettierPath, "/") {
+		// otherwise, it is a command found on the PATH
+		resolve(&ret.Formatter.PrettierPath)
+	}
+	return &ret, nil
+}
+
+// merge overrides the settings with those in the profile.
+func (p *ProjectConfig) merge(profile *ProjectConfig) {
+	override := func(dest *string, value string) {
+		if value != "" {
+			*dest = value
+		}
+	}
+	override(&p.Paths.Templates, profile.Paths.Templates)
+	if profile.Paths.Overlays != nil {
+		p.Paths.Overlays = profile.Paths.Overlays
+	}
+	override(&p.Paths.Generators, profile.Paths.Generators)
+	override(&p.Paths.Output, profile.Paths.Output)
+	override(&p.Paths.Help, profile.Paths.Help)
+	override(&p.Paths.Validators, profile.Paths.Validators)
+	override(&p.Formatter.PrettierPath, profile.Formatter.PrettierPath)
+	override(&p.Formatter.PrettierConfig, profile.Formatter.PrettierConfig)
+	if profile.Formatter.Gofmt != nil {
+		p.Formatter.Gofmt = profile.Formatter.Gofmt
+	}
+	if profile.Formatter.Prettier != nil {
+		p.Formatter.Prettier = profile.Formatter.Prettier
+	}
+	if profile.Generators.Enabled != nil {
+		p.Generators.Enabled = profile.Generators.Enabled
+	}
+}
+
+// FindProjectConfig returns the path to gomaker.toml in the given folder (the current
+// working directory if empty) or an empty string if there is none.
+func FindProjectConfig(folder string) string {
+	path := filepath.Join(folder, ProjectConfigFile)
+	if file.FileExists(path) {
+		return path
+	}
+	return ""
+}
+
+// SetProjectConfig sets the configuration in use. Pass nil to use none. Settings made
+// with the other setters, and the environment variables, take precedence over it.
+func SetProjectConfig(cfg *ProjectConfig) {
+	project = cfg
+	resetTemplatePath()
+	resetPrettierPath()
+}
+
+// GetProjectConfig returns the configuration in use (nil if there is none).
+func GetProjectConfig() *ProjectConfig {
+	return project
+}
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ProjectConfigFile)
+	contents := `default_profile = "core"
+
+[paths]
+templates = "code_gen/templates"
+help = "/abs/help"
+
+[formatter]
+prettier = false
+
+[profiles.core.paths]
+templates = "src/dev-tools/goMaker/templates"
+
+[profiles.desktop.generators]
+enabled = ["types"]
+`
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TB_MAKER_PROFILE", "")
+
+	cfg, err := LoadProjectConfig(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Profile() != "core" || cfg.Paths.Templates != filepath.Join(dir, "src/dev-tools/goMaker/templates") {
+		t.Errorf("default profile not applied: %s %s", cfg.Profile(), cfg.Paths.Templates)
+	}
+	if cfg.Paths.Help != "/abs/help" || cfg.Formatter.Prettier == nil || *cfg.Formatter.Prettier {
+		t.Errorf("base settings not kept: %+v", cfg)
+	}
+
+	cfg, err = LoadProjectConfig(path, "desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Paths.Templates != filepath.Join(dir, "code_gen/templates") || len(cfg.Generators.Enabled) != 1 {
+		t.Errorf("desktop profile not applied: %+v", cfg)
+	}
+
+	if _, err := LoadProjectConfig(path, "nope"); err == nil || !strings.Contains(err.Error(), "expected one of core, desktop") {
+		t.Errorf("expected an unknown profile error, got %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("[paths]\ntemplate = \"x\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProjectConfig(path, ""); err == nil || err.Error() != "line 2: unknown key paths.template" {
+		t.Errorf("expected an unknown key error, got %v", err)
+	}
+}
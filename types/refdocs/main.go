@@ -0,0 +1,299 @@
+// Refdocs writes reference_docs.go in the types package. It holds the doc comments and
+// signatures (with parameter names) that reflection cannot provide for the template
+// reference. It is run by go generate in the types folder:
+//
+//	go generate ./types
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const outputFile = "reference_docs.go"
+
+func main() {
+	code, err := generate(".")
+	if err == nil {
+		err = os.WriteFile(outputFile, code, 0644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "refdocs:", err)
+		os.Exit(1)
+	}
+}
+
+// generate returns the contents of reference_docs.go for the types package in dir.
+func generate(dir string) ([]byte, error) {
+	src, err := parseSources(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by refdocs; DO NOT EDIT.\n\npackage types\n\n")
+	buf.WriteString("// referenceFuncDocs holds the signature and doc comment of each function in the template FuncMap.\n")
+	writeMap(&buf, "referenceFuncDocs", src.funcDocs())
+	buf.WriteString("\n// referenceTypeDocs holds the doc comments of each receiver (keyed by its name) and of its\n")
+	buf.WriteString("// fields and methods (keyed by Receiver.Name). Methods also have their signatures.\n")
+	writeMap(&buf, "referenceTypeDocs", src.typeDocs())
+	return format.Source(buf.Bytes())
+}
+
+type referenceDoc struct {
+	signature string
+	doc       string
+}
+
+func writeMap(buf *bytes.Buffer, name string, docs map[string]referenceDoc) {
+	keys := make([]string, 0, len(docs))
+	for key := range docs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(buf, "var %s = map[string]referenceDoc{\n", name)
+	for _, key := range keys {
+		d := docs[key]
+		fields := []string{}
+		if d.signature != "" {
+			fields = append(fields, "Signature: "+strconv.Quote(d.signature))
+		}
+		if d.doc != "" {
+			fields = append(fields, "Doc: "+strconv.Quote(d.doc))
+		}
+		fmt.Fprintf(buf, "%s: {%s},\n", strconv.Quote(key), strings.Join(fields, ", "))
+	}
+	buf.WriteString("}\n")
+}
+
+// sources is the parsed source of the types package.
+type sources struct {
+	fset      *token.FileSet
+	pkg       *doc.Package
+	funcMap   *ast.FuncDecl
+	comments  []*ast.CommentGroup // the comments inside getFuncMap
+	receivers []string
+}
+
+func parseSources(dir string) (*sources, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	src := &sources{fset: token.NewFileSet()}
+	files := []*ast.File{}
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") || filepath.Base(path) == outputFile {
+			continue
+		}
+		f, err := parser.ParseFile(src.fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil && d.Name.Name == "getFuncMap" {
+					src.funcMap = d
+					for _, cg := range f.Comments {
+						if cg.Pos() > d.Body.Lbrace && cg.End() < d.Body.Rbrace {
+							src.comments = append(src.comments, cg)
+						}
+					}
+				}
+			case *ast.GenDecl:
+				src.receivers = append(src.receivers, referenceReceivers(d)...)
+			}
+		}
+		files = append(files, f)
+	}
+	if src.funcMap == nil {
+		return nil, fmt.Errorf("could not find getFuncMap in %s", dir)
+	}
+	if len(src.receivers) == 0 {
+		return nil, fmt.Errorf("could not find referenceReceivers in %s", dir)
+	}
+
+	src.pkg, err = doc.NewFromFiles(src.fset, files, "github.com/TrueBlocks/goMaker/v6/types", doc.PreserveAST)
+	if err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// referenceReceivers returns the names of the types listed in the referenceReceivers
+// variable (each one given as &Type{}) if decl declares it.
+func referenceReceivers(decl *ast.GenDecl) []string {
+	ret := []string{}
+	for _, spec := range decl.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok || len(vs.Names) != 1 || vs.Names[0].Name != "referenceReceivers" || len(vs.Values) != 1 {
+			continue
+		}
+		lit, ok := vs.Values[0].(*ast.CompositeLit)
+		if !ok {
+			continue
+		}
+		ast.Inspect(lit, func(n ast.Node) bool {
+			if u, ok := n.(*ast.UnaryExpr); ok && u.Op == token.AND {
+				if cl, ok := u.X.(*ast.CompositeLit); ok {
+					if ident, ok := cl.Type.(*ast.Ident); ok {
+						ret = append(ret, ident.Name)
+					}
+				}
+				return false
+			}
+			return true
+		})
+	}
+	return ret
+}
+
+// funcDocs documents each function defined in getFuncMap. The documentation is the
+// comment preceding the function's definition. If there is none and the function only
+// calls another function in this package, it is that function's doc comment.
+func (src *sources) funcDocs() map[string]referenceDoc {
+	docFuncs := map[string]string{}
+	for _, f := range src.pkg.Funcs {
+		docFuncs[f.Name] = f.Doc
+	}
+	for _, t := range src.pkg.Types {
+		for _, f := range t.Funcs {
+			docFuncs[f.Name] = f.Doc
+		}
+	}
+
+	ret := map[string]referenceDoc{}
+	for _, stmt := range src.funcMap.Body.List {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			continue
+		}
+		ident, ok := assign.Lhs[0].(*ast.Ident)
+		if !ok {
+			continue
+		}
+		lit, ok := assign.Rhs[0].(*ast.FuncLit)
+		if !ok {
+			continue
+		}
+
+		d := referenceDoc{
+			signature: ident.Name + strings.TrimPrefix(nodeString(lit.Type), "func"),
+			doc:       src.precedingComment(lit.Pos()),
+		}
+		if d.doc == "" {
+			if expr := singleReturn(lit); expr != nil {
+				d.doc = "Returns `" + nodeString(expr) + "`."
+				if call, ok := expr.(*ast.CallExpr); ok {
+					if ident, ok := call.Fun.(*ast.Ident); ok && docFuncs[ident.Name] != "" {
+						d.doc = docFuncs[ident.Name]
+					}
+				}
+			}
+		}
+		d.doc = oneLine(d.doc)
+		ret[ident.Name] = d
+	}
+	return ret
+}
+
+// precedingComment returns the comment on the lines immediately before pos.
+func (src *sources) precedingComment(pos token.Pos) string {
+	line := src.fset.Position(pos).Line
+	for _, cg := range src.comments {
+		if src.fset.Position(cg.End()).Line == line-1 {
+			return cg.Text()
+		}
+	}
+	return ""
+}
+
+// singleReturn returns the expression if the function's body is a single return statement.
+func singleReturn(lit *ast.FuncLit) ast.Expr {
+	if len(lit.Body.List) != 1 {
+		return nil
+	}
+	if ret, ok := lit.Body.List[0].(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+		return ret.Results[0]
+	}
+	return nil
+}
+
+// typeDocs documents each receiver and its exported fields and methods.
+func (src *sources) typeDocs() map[string]referenceDoc {
+	docTypes := map[string]*doc.Type{}
+	for _, t := range src.pkg.Types {
+		docTypes[t.Name] = t
+	}
+
+	ret := map[string]referenceDoc{}
+	for _, name := range src.receivers {
+		dt := docTypes[name]
+		if dt == nil {
+			continue
+		}
+		if d := oneLine(dt.Doc); d != "" {
+			ret[name] = referenceDoc{doc: d}
+		}
+		for _, spec := range dt.Decl.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			for _, field := range st.Fields.List {
+				text := ""
+				if field.Doc != nil {
+					text = field.Doc.Text()
+				} else if field.Comment != nil {
+					text = field.Comment.Text()
+				}
+				for _, fieldName := range field.Names {
+					if d := oneLine(text); d != "" && fieldName.IsExported() {
+						ret[name+"."+fieldName.Name] = referenceDoc{doc: d}
+					}
+				}
+			}
+		}
+		for _, m := range dt.Methods {
+			if !ast.IsExported(m.Name) {
+				continue
+			}
+			decl := *m.Decl
+			decl.Recv, decl.Doc, decl.Body = nil, nil, nil
+			ret[name+"."+m.Name] = referenceDoc{
+				signature: strings.TrimPrefix(nodeString(&decl), "func "),
+				doc:       oneLine(m.Doc),
+			}
+		}
+	}
+	return ret
+}
+
+func nodeString(node any) string {
+	var buf bytes.Buffer
+	_ = printer.Fprint(&buf, token.NewFileSet(), node)
+	return buf.String()
+}
+
+// oneLine joins the lines of a doc comment.
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,225 @@
+package types
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+//go:generate go run ./refdocs
+
+// Reference lists everything a template may call: the functions in the template FuncMap
+// and the exported fields and methods of each receiver.
+type Reference struct {
+	Functions []ReferenceEntry    `json:"functions"`
+	Receivers []ReceiverReference `json:"receivers"`
+}
+
+// ReceiverReference describes one type that templates are applied to (or reach through
+// a receiver's fields and methods).
+type ReceiverReference struct {
+	Name    string           `json:"name"`
+	UsedBy  string           `json:"usedBy"`
+	Doc     string           `json:"doc,omitempty"`
+	Fields  []ReferenceEntry `json:"fields"`
+	Methods []ReferenceEntry `json:"methods"`
+}
+
+// ReferenceEntry is a single field, method, or function. For fields, Type is the type
+// of the field. For methods and functions, it is the type of the result(s).
+type ReferenceEntry struct {
+	Name      string `json:"name"`
+	Signature string `json:"signature,omitempty"`
+	Type      string `json:"type"`
+	Doc       string `json:"doc,omitempty"`
+}
+
+var referenceReceivers = []struct {
+	value  any
+	usedBy string
+}{
+	{&CodeBase{}, "codebase and groups templates"},
+	{&Command{}, "routes templates"},
+	{&Structure{}, "types templates"},
+	{&Facet{}, "types templates with a facet in their name"},
+	{&Member{}, "reached through Structure"},
+	{&Option{}, "reached through Command"},
+	{&Store{}, "reached through Structure and Facet"},
+	{&Handler{}, "reached through Command"},
+}
+
+// referenceDoc is the doc comment (and, for functions and methods, the signature with its
+// parameter names) of an entry in the reference. Reflection provides everything else.
+// They are extracted from the package's source by go generate (see refdocs).
+type referenceDoc struct {
+	Signature string
+	Doc       string
+}
+
+// GetReference builds the reference using reflection for what is available and the
+// package's doc comments for what it does.
+func GetReference() (*Reference, error) {
+	ret := &Reference{
+		Functions: funcReference(),
+	}
+	for _, r := range referenceReceivers {
+		ret.Receivers = append(ret.Receivers, receiverReference(reflect.TypeOf(r.value), r.usedBy))
+	}
+	return ret, nil
+}
+
+// funcReference describes each entry in the FuncMap.
+func funcReference() []ReferenceEntry {
+	ret := []ReferenceEntry{}
+	for name, fn := range getFuncMap() {
+		entry := ReferenceEntry{
+			Name:      name,
+			Signature: name + strings.TrimPrefix(typeString(reflect.TypeOf(fn)), "func"),
+			Type:      resultTypes(reflect.TypeOf(fn)),
+		}
+		if d, ok := referenceFuncDocs[name]; ok {
+			entry.Signature = d.Signature
+			entry.Doc = cleanDoc(name, d.Doc)
+		}
+		ret = append(ret, entry)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+func receiverReference(ptr reflect.Type, usedBy string) ReceiverReference {
+	t := ptr.Elem()
+	ret := ReceiverReference{
+		Name:    t.Name(),
+		UsedBy:  usedBy,
+		Doc:     cleanDoc(t.Name(), referenceTypeDocs[t.Name()].Doc),
+		Fields:  []ReferenceEntry{},
+		Methods: []ReferenceEntry{},
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		ret.Fields = append(ret.Fields, ReferenceEntry{
+			Name: f.Name,
+			Type: typeString(f.Type),
+			Doc:  cleanDoc(f.Name, referenceTypeDocs[t.Name()+"."+f.Name].Doc),
+		})
+	}
+
+	for i := 0; i < ptr.NumMethod(); i++ {
+		m := ptr.Method(i)
+		if !templateCallable(m.Type) {
+			continue
+		}
+		entry := ReferenceEntry{
+			Name: m.Name,
+			Type: resultTypes(m.Type),
+		}
+		params := []string{}
+		for j := 1; j < m.Type.NumIn(); j++ {
+			params = append(params, typeString(m.Type.In(j)))
+		}
+		entry.Signature = m.Name + "(" + strings.Join(params, ", ") + ")"
+		if d, ok := referenceTypeDocs[t.Name()+"."+m.Name]; ok {
+			entry.Signature = d.Signature
+			entry.Doc = cleanDoc(m.Name, d.Doc)
+		}
+		ret.Methods = append(ret.Methods, entry)
+	}
+	return ret
+}
+
+// templateCallable returns true if text/template can call a function of this type: it
+// must return a single value or a value and an error.
+func templateCallable(fn reflect.Type) bool {
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+	switch fn.NumOut() {
+	case 1:
+		return true
+	case 2:
+		return fn.Out(1) == errorType
+	}
+	return false
+}
+
+// resultTypes returns the result types of a function type as they would be written in Go.
+func resultTypes(fn reflect.Type) string {
+	results := []string{}
+	for i := 0; i < fn.NumOut(); i++ {
+		results = append(results, typeString(fn.Out(i)))
+	}
+	if len(results) > 1 {
+		return "(" + strings.Join(results, ", ") + ")"
+	}
+	return strings.Join(results, "")
+}
+
+func typeString(t reflect.Type) string {
+	return strings.ReplaceAll(strings.ReplaceAll(t.String(), "types.", ""), "interface {}", "any")
+}
+
+// cleanDoc joins the lines of a doc comment and removes the leading "Name - " (or "Name ")
+// used by the comments in this package.
+func cleanDoc(name, text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if strings.Trim(text, "-=/ ") == "" {
+		// a separator, not a comment
+		return ""
+	}
+	for _, prefix := range []string{name + " - ", name + " -- ", name + ": ", name + " "} {
+		if strings.HasPrefix(text, prefix) {
+			return FirstUpper(strings.TrimPrefix(text, prefix))
+		}
+	}
+	return text
+}
+
+// Markdown formats the reference as a markdown document.
+func (r *Reference) Markdown() string {
+	escape := func(s string) string {
+		return strings.ReplaceAll(s, "|", "\\|")
+	}
+
+	lines := []string{
+		"# Template Reference",
+		"",
+		"This file is generated by `goMaker reference`. It lists the functions available to every template",
+		"and the fields and methods of each receiver.",
+		"",
+		"## Functions",
+		"",
+		"| Function | Returns | Description |",
+		"| -------- | ------- | ----------- |",
+	}
+	for _, f := range r.Functions {
+		lines = append(lines, "| `"+escape(f.Signature)+"` | `"+escape(f.Type)+"` | "+escape(f.Doc)+" |")
+	}
+
+	for _, rcv := range r.Receivers {
+		lines = append(lines, "", "## "+rcv.Name, "")
+		if rcv.Doc != "" {
+			lines = append(lines, rcv.Doc, "")
+		}
+		lines = append(lines, "Used by "+rcv.UsedBy+".", "")
+		lines = append(lines, "### Fields", "")
+		lines = append(lines, "| Field | Type | Description |", "| ----- | ---- | ----------- |")
+		for _, f := range rcv.Fields {
+			lines = append(lines, "| `."+f.Name+"` | `"+escape(f.Type)+"` | "+escape(f.Doc)+" |")
+		}
+		lines = append(lines, "", "### Methods", "")
+		lines = append(lines, "| Method | Returns | Description |", "| ------ | ------- | ----------- |")
+		for _, m := range rcv.Methods {
+			returns := ""
+			if m.Type != "" {
+				returns = "`" + escape(m.Type) + "`"
+			}
+			lines = append(lines, "| `."+escape(m.Signature)+"` | "+returns+" | "+escape(m.Doc)+" |")
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,357 @@
+// Code generated by refdocs; DO NOT EDIT.
+
+package types
+
+// referenceFuncDocs holds the signature and doc comment of each function in the template FuncMap.
+var referenceFuncDocs = map[string]referenceDoc{
+	"add":           {Signature: "add(a, b int) int", Doc: "Returns `a + b`."},
+	"append":        {Signature: "append(existing []string, add string) []string", Doc: "Returns `append(existing, add)`."},
+	"apply":         {Signature: "apply(array []string, tmplStr, sep string) string", Doc: "apply executes tmplStr against each item of array and joins the results with sep."},
+	"cond":          {Signature: "cond(t bool, a, b any) any", Doc: "cond returns a if t is true and b otherwise."},
+	"contains":      {Signature: "contains(s, substr string) bool", Doc: "Returns `strings.Contains(s, substr)`."},
+	"firstLower":    {Signature: "firstLower(s string) string", Doc: "Returns `FirstLower(s)`."},
+	"firstUpper":    {Signature: "firstUpper(s string) string", Doc: "Returns `FirstUpper(s)`."},
+	"hasPrefix":     {Signature: "hasPrefix(s, prefix string) bool", Doc: "Returns `strings.HasPrefix(s, prefix)`."},
+	"hasSuffix":     {Signature: "hasSuffix(s, suffix string) bool", Doc: "Returns `strings.HasSuffix(s, suffix)`."},
+	"hotkey":        {Signature: "hotkey(n int) string", Doc: "hotkey returns the hotkey and altHotkey properties for the n-th item of a menu."},
+	"max":           {Signature: "max(a, b int) int", Doc: "Returns `max(a, b)`."},
+	"min":           {Signature: "min(a, b int) int", Doc: "Returns `min(a, b)`."},
+	"or":            {Signature: "or(a, b bool) bool", Doc: "Returns `a || b`."},
+	"regexCompile":  {Signature: "regexCompile(pattern string) *regexp.Regexp", Doc: "regexCompile compiles pattern for use with regexReplace."},
+	"regexReplace":  {Signature: "regexReplace(re *regexp.Regexp, input, replacement string) string", Doc: "Returns `re.ReplaceAllString(input, replacement)`."},
+	"replace":       {Signature: "replace(str, find, rep string) string", Doc: "Returns `strings.ReplaceAll(str, find, rep)`."},
+	"split":         {Signature: "split(s string, k string) []string", Doc: "Returns `strings.Split(s, k)`."},
+	"sub":           {Signature: "sub(a, b int) int", Doc: "Returns `a - b`."},
+	"toCamel":       {Signature: "toCamel(s string) string", Doc: "Returns `CamelCase(s)`."},
+	"toHeader":      {Signature: "toHeader(s string) string", Doc: "toHeader splits a camel case name into capitalized words dropping a leading N or Is."},
+	"toLower":       {Signature: "toLower(s string) string", Doc: "Returns `Lower(s)`."},
+	"toLowerPlural": {Signature: "toLowerPlural(s string) string", Doc: "Returns `Lower(Plural(s))`."},
+	"toPlural":      {Signature: "toPlural(s string) string", Doc: "Returns `Plural(s)`."},
+	"toProper":      {Signature: "toProper(s string) string", Doc: "Returns `Proper(s)`."},
+	"toSingular":    {Signature: "toSingular(s string) string", Doc: "Returns `Singular(s)`."},
+	"toUpper":       {Signature: "toUpper(s string) string", Doc: "Returns `Upper(s)`."},
+	"trim":          {Signature: "trim(s string) string", Doc: "Returns `strings.TrimSpace(s)`."},
+}
+
+// referenceTypeDocs holds the doc comments of each receiver (keyed by its name) and of its
+// fields and methods (keyed by Receiver.Name). Methods also have their signatures.
+var referenceTypeDocs = map[string]referenceDoc{
+	"CodeBase":                     {Doc: "CodeBase - the top-level structure for the codebase which carries an array of Commands which describe, in combination with the Structures, the command line options, help file text, and SDK definitions."},
+	"CodeBase.AffectedBy":          {Signature: "AffectedBy(previous *CodeBase, changed []string) (targets *Targets)", Doc: "AffectedBy returns the outputs that need to be regenerated given the changed paths. previous is the codebase as it was before the changes (it may be the same as cb if the data model did not change). A nil return means everything is affected."},
+	"CodeBase.Description":         {Signature: "Description() string", Doc: "Description - returns the description of the codebase for the openapi.yaml file"},
+	"CodeBase.Explain":             {Signature: "Explain(path string) (ret []Output, err error)", Doc: "Explain returns the outputs (there may be more than one) that write to the given path along with the intro, notes, and partial files each one reads."},
+	"CodeBase.ExplainLine":         {Signature: "ExplainLine(o Output, line int) (ret *LineSource, err error)", Doc: "ExplainLine reports which line of o's template produced the given line (counting from one) of the generated file. It renders the template again, without writing anything, and aligns the result with the file on disk."},
+	"CodeBase.FinishLoad":          {Signature: "FinishLoad(unused string, baseTypes []Structure, options []Option, structMap map[string]Structure) error"},
+	"CodeBase.Generate":            {Signature: "Generate()", Doc: "Generate generates the code for the codebase using the given templates."},
+	"CodeBase.GenerateOnly":        {Signature: "GenerateOnly(targets *Targets) (err error)", Doc: "GenerateOnly generates the outputs selected by targets (or everything if targets is nil). It stops at and returns the first error, including errors in the templates themselves."},
+	"CodeBase.GroupList":           {Signature: "GroupList(filter string) []Command"},
+	"CodeBase.Handlers":            {Signature: "Handlers() string"},
+	"CodeBase.LoadMembers":         {Signature: "LoadMembers(basePath string, structMap map[string]Structure) error", Doc: "LoadMembers reads the members of the structures in structMap from the .csv files under basePath. Structures whose members were given inline in their .toml file (see LoadStructures) may not also have a .csv file."},
+	"CodeBase.LoadStructures":      {Signature: "LoadStructures(basePath string, callBack func(*Structure, *any) (bool, error), structMap map[string]Structure) error", Doc: "LoadStructures reads each .toml file under basePath, normalizes its settings with callBack and its facets, and adds the result to structMap (if callBack returns true)."},
+	"CodeBase.NewEvaluator":        {Signature: "NewEvaluator(r EvalReceiver) (*Evaluator, error)", Doc: "NewEvaluator returns an Evaluator for the given receiver."},
+	"CodeBase.NewRoute":            {Signature: "NewRoute(opts NewRouteOptions) (*Scaffold, error)", Doc: "NewRoute adds the rows for a new command to cmd-line-options.csv at the end of its group and creates its readme intro. The command's num is the next free one in the group."},
+	"CodeBase.NewType":             {Signature: "NewType(opts NewTypeOptions) (*Scaffold, error)", Doc: "NewType creates the class definition, fields, and model intro for a new data model. The doc_route is the next free one in the group."},
+	"CodeBase.Orphans":             {Signature: "Orphans() ([]ManifestEntry, error)", Doc: "Orphans returns the files listed in the manifest that the generators no longer produce (for example, because a route was removed or a type's output path changed)."},
+	"CodeBase.Outputs":             {Signature: "Outputs() (ret []Output, err error)", Doc: "Outputs returns every file the generators would produce for this codebase without rendering any of them. The order matches the order used by Generate."},
+	"CodeBase.ProcessFile":         {Signature: "ProcessFile(source, group, reason string) (err error)", Doc: "ProcessFile processes a single file, applying the template to it and writing the result to the destination."},
+	"CodeBase.ProcessGroupFile":    {Signature: "ProcessGroupFile(source, group, reason string) (err error)", Doc: "ProcessGroupFile processes a single file, applying the template to it and writing the result to the destination."},
+	"CodeBase.RouteToGroup":        {Signature: "RouteToGroup(route string) string", Doc: "RouteToGroup - returns the group given a route"},
+	"CodeBase.SortedStructs":       {Signature: "SortedStructs() []Structure"},
+	"CodeBase.String":              {Signature: "String() string", Doc: "String - returns a JSON representation of the codebase"},
+	"CodeBase.SummaryTag":          {Signature: "SummaryTag(filter string) string", Doc: "SummaryTag - returns a summary of the commands used in the helpText"},
+	"CodeBase.TagSummary":          {Signature: "TagSummary() string", Doc: "TagSummary - returns a summary of the tags used in the openapi.yaml file"},
+	"CodeBase.TypeToGroup":         {Signature: "TypeToGroup(typ string) string", Doc: "TypeToGroup - returns the group given a type"},
+	"CodeBase.Validate":            {Signature: "Validate() error", Doc: "Validate reports every problem it finds in the codebase as a diagnostic. It returns an error if any errors have been reported."},
+	"CodeBase.VerifyValidators":    {Signature: "VerifyValidators() ([]Diagnostic, error)", Doc: "VerifyValidators checks the enum validators in each route's hand-written validate.go (see verifyValidators). It is not part of loading, so a stale validate.go does not stop the model from loading. It returns the problems found (which are also added to Diagnostics) and an error if any of them is an error (or, with warnings-as-errors, a warning)."},
+	"CodeBase.Version":             {Signature: "Version(verbose bool) string", Doc: "Version - returns the version of the codebase"},
+	"CodeBase.Views":               {Signature: "Views() string"},
+	"Command.AddCaps":              {Signature: "AddCaps() string", Doc: "AddCaps for tag {{.AddCaps}}"},
+	"Command.AliasStr":             {Signature: "AliasStr() string", Doc: "AliasStr for tag {{.AliasStr}}}"},
+	"Command.AnyCrud":              {Signature: "AnyCrud() string"},
+	"Command.BaseTypes":            {Signature: "BaseTypes() string"},
+	"Command.CapsMapAndArray":      {Signature: "CapsMapAndArray() (map[string]bool, []string)"},
+	"Command.Clean":                {Signature: "Clean()"},
+	"Command.Cruds":                {Signature: "Cruds() string"},
+	"Command.DefaultsApi":          {Signature: "DefaultsApi(showConfig bool) string", Doc: "DefaultsApi for tag {{.DefaultsApi}}"},
+	"Command.Deprecated":           {Signature: "Deprecated() string"},
+	"Command.DeprecatedTransfer":   {Signature: "DeprecatedTransfer() string"},
+	"Command.EnsConvert1":          {Signature: "EnsConvert1() string", Doc: "EnsConvert1 for tag {{.EnsConvert1}}"},
+	"Command.EnsConvert2":          {Signature: "EnsConvert2() string", Doc: "EnsConvert2 for tag {{.EnsConvert2}}"},
+	"Command.Enums1":               {Signature: "Enums1() string", Doc: "Enums1 for tag {{.Enums1}}"},
+	"Command.Enums2":               {Signature: "Enums2() string", Doc: "Enums2 for tag {{.Enums2}}"},
+	"Command.Enums3":               {Signature: "Enums3() string", Doc: "Enums3 for tag {{.Enums3}}"},
+	"Command.Example":              {Signature: "Example() string"},
+	"Command.FirstPositional":      {Signature: "FirstPositional() string"},
+	"Command.FlagAliases":          {Signature: "FlagAliases() string"},
+	"Command.FuzzerInits":          {Signature: "FuzzerInits() string"},
+	"Command.FuzzerSwitches":       {Signature: "FuzzerSwitches() string", Doc: "----------------------------------------------------------------------"},
+	"Command.GetGlobs":             {Signature: "GetGlobs() string"},
+	"Command.GoDefs":               {Signature: "GoDefs() string", Doc: "GoDefs for tag {{.GoDefs}}"},
+	"Command.GroupAlias":           {Signature: "GroupAlias(reason string) string"},
+	"Command.GroupIntro":           {Signature: "GroupIntro(reason string) string"},
+	"Command.GroupMarkdowns":       {Signature: "GroupMarkdowns(reason, filter string) string"},
+	"Command.GroupMenu":            {Signature: "GroupMenu(reason string) string"},
+	"Command.GroupName":            {Signature: "GroupName() string"},
+	"Command.GroupTitle":           {Signature: "GroupTitle() string"},
+	"Command.HandlerCode":          {Signature: "HandlerCode() string"},
+	"Command.HandlerRows":          {Signature: "HandlerRows() string"},
+	"Command.HasAddrs":             {Signature: "HasAddrs() bool"},
+	"Command.HasCrud":              {Signature: "HasCrud() bool"},
+	"Command.HasDeprecated":        {Signature: "HasDeprecated() bool"},
+	"Command.HasEnums":             {Signature: "HasEnums() bool"},
+	"Command.HasExample":           {Signature: "HasExample() bool"},
+	"Command.HasFlagAliases":       {Signature: "HasFlagAliases() bool"},
+	"Command.HasHidden":            {Signature: "HasHidden() bool"},
+	"Command.HasNotes":             {Signature: "HasNotes() bool"},
+	"Command.HasPositionals":       {Signature: "HasPositionals() bool"},
+	"Command.HasSdkEndpoints":      {Signature: "HasSdkEndpoints() bool"},
+	"Command.HelpDataModels":       {Signature: "HelpDataModels() string"},
+	"Command.HelpIntro":            {Signature: "HelpIntro() string"},
+	"Command.HelpLinks":            {Signature: "HelpLinks() string"},
+	"Command.HelpNotes":            {Signature: "HelpNotes() string"},
+	"Command.HelpText":             {Signature: "HelpText() string"},
+	"Command.IsRoute":              {Signature: "IsRoute() bool"},
+	"Command.OptFields":            {Signature: "OptFields() string", Doc: "OptFields for tag {{.OptFields}}"},
+	"Command.PackageComments":      {Signature: "PackageComments() string"},
+	"Command.Pkg":                  {Signature: "Pkg() string", Doc: "Pkg for tag {{.Pkg}}"},
+	"Command.ProcessFile":          {Signature: "ProcessFile(source, group, reason string) (err error)", Doc: "ProcessFile processes a single file, applying the template to it and writing the result to the destination."},
+	"Command.ProducedByDescr":      {Signature: "ProducedByDescr() string"},
+	"Command.ProducedByList":       {Signature: "ProducedByList() string"},
+	"Command.PyGlobals":            {Signature: "PyGlobals() string"},
+	"Command.PyOptions":            {Signature: "PyOptions() string"},
+	"Command.ReadmeFooter":         {Signature: "ReadmeFooter() string"},
+	"Command.ReadmeName":           {Signature: "ReadmeName() string"},
+	"Command.RequestOpts":          {Signature: "RequestOpts() string", Doc: "RequestOpts for tag {{.RequestOpts}}"},
+	"Command.ReturnTypes":          {Signature: "ReturnTypes() string", Doc: "----------------------------------------------------------------------"},
+	"Command.ReturnTypesArray":     {Signature: "ReturnTypesArray() []string", Doc: "----------------------------------------------------------------------"},
+	"Command.SdkEndpoints":         {Signature: "SdkEndpoints() string"},
+	"Command.TestLogs":             {Signature: "TestLogs() string", Doc: "TestLogs for tag {{.TestLogs}}"},
+	"Command.TsOptions":            {Signature: "TsOptions() string"},
+	"Command.TsOptions2":           {Signature: "TsOptions2() string"},
+	"Command.TsReturns":            {Signature: "TsReturns() string"},
+	"Command.TsTypes":              {Signature: "TsTypes() string"},
+	"Command.TypeToGroup":          {Signature: "TypeToGroup(t string) string"},
+	"Command.YamlGlobals":          {Signature: "YamlGlobals() string"},
+	"Facet.Confirms":               {Doc: "actions requiring confirmation (parsed from -confirm suffix)"},
+	"Facet.Divider":                {Signature: "Divider() string"},
+	"Facet.HasDivider":             {Signature: "HasDivider() bool"},
+	"Facet.HasViewType":            {Signature: "HasViewType() bool"},
+	"Facet.HeaderActionsBe":        {Signature: "HeaderActionsBe(facet string) string"},
+	"Facet.IsCustom":               {Signature: "IsCustom() bool"},
+	"Facet.IsCustomFacet":          {Signature: "IsCustomFacet() bool"},
+	"Facet.IsCustomPanel":          {Signature: "IsCustomPanel() bool"},
+	"Facet.IsForm":                 {Signature: "IsForm() bool"},
+	"Facet.IsTable":                {Signature: "IsTable() bool"},
+	"Facet.NavigateTo":             {Signature: "NavigateTo() string"},
+	"Facet.NeedsCustomRenderer":    {Signature: "NeedsCustomRenderer() bool"},
+	"Facet.NormalizeActions":       {Signature: "NormalizeActions()", Doc: "NormalizeActions processes any \"-confirm\" suffix, storing the base action name and a parallel confirmation flag."},
+	"Facet.RowActionsBe":           {Signature: "RowActionsBe(facet string) string"},
+	"Facet.SingleStore":            {Signature: "SingleStore() string"},
+	"Facet.SortFunc":               {Signature: "SortFunc() string"},
+	"Facet.Store":                  {Doc: "This will be parsed into StoreName and StoreSource"},
+	"Facet.ValidateActions":        {Signature: "ValidateActions() error"},
+	"Facet.ValidateAll":            {Signature: "ValidateAll() error"},
+	"Facet.ValidatePanel":          {Signature: "ValidatePanel() error"},
+	"Facet.ValidateViewType":       {Signature: "ValidateViewType() error"},
+	"Handler.Handler":              {Signature: "Handler() string"},
+	"Handler.Test":                 {Signature: "Test() string"},
+	"Member.Align":                 {Signature: "Align() string"},
+	"Member.BaseType":              {Signature: "BaseType() string"},
+	"Member.Container":             {Signature: "Container() string"},
+	"Member.Fmt":                   {Signature: "Fmt() string", Doc: "Fmt returns the format hint for this field. If fmt=value is present in attributes, it returns that value; otherwise defaults to the field's Type."},
+	"Member.GetColumnLabel":        {Signature: "GetColumnLabel() string", Doc: "GetColumnLabel returns the appropriate column label for this field"},
+	"Member.GetDetailLabel":        {Signature: "GetDetailLabel() string", Doc: "GetDetailLabel returns the appropriate detail label for this field"},
+	"Member.GetFormatter":          {Signature: "GetFormatter() string", Doc: "GetFormatter returns the appropriate formatter string for this field Updated to preserve CSV semantic types and fix boolean field handling"},
+	"Member.GoName":                {Signature: "GoName() string"},
+	"Member.GoType":                {Signature: "GoType() string"},
+	"Member.HasUpgrade":            {Signature: "HasUpgrade() bool"},
+	"Member.IsAddress":             {Signature: "IsAddress(s *Structure) bool"},
+	"Member.IsBool":                {Signature: "IsBool() bool"},
+	"Member.IsCalc":                {Signature: "IsCalc() bool"},
+	"Member.IsEmbed":               {Signature: "IsEmbed() bool"},
+	"Member.IsInit":                {Signature: "IsInit() bool"},
+	"Member.IsItems":               {Signature: "IsItems() bool"},
+	"Member.IsNoTable":             {Signature: "IsNoTable() bool"},
+	"Member.IsNoTag":               {Signature: "IsNoTag() bool"},
+	"Member.IsObject":              {Signature: "IsObject() bool"},
+	"Member.IsOmitEmpty":           {Signature: "IsOmitEmpty() bool"},
+	"Member.IsRemoved":             {Signature: "IsRemoved() bool"},
+	"Member.IsRequired":            {Signature: "IsRequired() bool"},
+	"Member.IsSimpField":           {Signature: "IsSimpField() bool"},
+	"Member.IsSortable":            {Signature: "IsSortable() bool"},
+	"Member.IsString":              {Signature: "IsString() bool"},
+	"Member.Lower":                 {Signature: "Lower() string"},
+	"Member.LowerSingular":         {Signature: "LowerSingular() string"},
+	"Member.MarkdownDescription":   {Signature: "MarkdownDescription() string"},
+	"Member.MarkdownType":          {Signature: "MarkdownType() string"},
+	"Member.MarshalCode":           {Signature: "MarshalCode() string", Doc: "MarshalCode writes the writer code for caching this item"},
+	"Member.MemTsType":             {Signature: "MemTsType() string"},
+	"Member.NeedsPtr":              {Signature: "NeedsPtr() bool"},
+	"Member.Pos":                   {Signature: "Pos() Position", Doc: "Pos returns where the member is defined."},
+	"Member.ReadOnly":              {Signature: "ReadOnly() bool"},
+	"Member.SortName":              {Signature: "SortName() string"},
+	"Member.String":                {Signature: "String() string"},
+	"Member.Tag":                   {Signature: "Tag() string"},
+	"Member.TypeToGroup":           {Signature: "TypeToGroup(t string) string"},
+	"Member.UiType":                {Signature: "UiType() string"},
+	"Member.UnmarshalCode":         {Signature: "UnmarshalCode() string", Doc: "UnmarshalCode writes the reader code for caching this item"},
+	"Member.Validate":              {Signature: "Validate() bool"},
+	"Member.Width":                 {Signature: "Width() int64"},
+	"Member.YamlType":              {Signature: "YamlType() string"},
+	"Option.AssignReceive":         {Signature: "AssignReceive() string"},
+	"Option.Clear":                 {Signature: "Clear() string"},
+	"Option.CmdDefault":            {Signature: "CmdDefault() string"},
+	"Option.CmdTsType":             {Signature: "CmdTsType() string"},
+	"Option.CobraPart":             {Signature: "CobraPart() string"},
+	"Option.CobraType":             {Signature: "CobraType() string"},
+	"Option.Default":               {Signature: "Default() string"},
+	"Option.DefaultApi":            {Signature: "DefaultApi() string"},
+	"Option.DeprecatedNotDefault":  {Signature: "DeprecatedNotDefault() string"},
+	"Option.Deprecator":            {Signature: "Deprecator() string"},
+	"Option.DeprecatorIsDefault":   {Signature: "DeprecatorIsDefault() string"},
+	"Option.DeprecatorRep":         {Signature: "DeprecatorRep() string"},
+	"Option.DescrCaps":             {Signature: "DescrCaps() string"},
+	"Option.DescriptionEx":         {Signature: "DescriptionEx() string"},
+	"Option.DocType":               {Signature: "DocType() string"},
+	"Option.EnsConvert":            {Signature: "EnsConvert() string"},
+	"Option.Enum1":                 {Signature: "Enum1() string", Doc: "Enum1 for tag {{.Enum1}}"},
+	"Option.Enum2":                 {Signature: "Enum2() string", Doc: "Enum2 for tag {{.Enum2}}"},
+	"Option.EnumCases":             {Signature: "EnumCases() string"},
+	"Option.EnumChoices":           {Signature: "EnumChoices() string"},
+	"Option.EnumDef":               {Signature: "EnumDef() string"},
+	"Option.EnumList":              {Signature: "EnumList() string"},
+	"Option.EnumMap":               {Signature: "EnumMap() string"},
+	"Option.EnumName":              {Signature: "EnumName() string"},
+	"Option.EnumNone":              {Signature: "EnumNone() string"},
+	"Option.EnumTag":               {Signature: "EnumTag(e string) string", Doc: "EnumTag returns the prefix of the constant for the enum's value e (or \"none\"). It is the first letters of the route and option unless the option's attributes give one (prefix=XY) or that would produce the same constant as another enum (see nameEnums)."},
+	"Option.EnumTypes":             {Signature: "EnumTypes() []string"},
+	"Option.FindDeprecator":        {Signature: "FindDeprecator() *Option"},
+	"Option.FlagAliasTarget":       {Signature: "FlagAliasTarget() string"},
+	"Option.FuzzerSwitch":          {Signature: "FuzzerSwitch() string"},
+	"Option.GetBools":              {Signature: "GetBools() string"},
+	"Option.GetEnums":              {Signature: "GetEnums() string"},
+	"Option.GetNotFuzzed":          {Signature: "GetNotFuzzed() string"},
+	"Option.GetOthers":             {Signature: "GetOthers() string"},
+	"Option.GoDef":                 {Signature: "GoDef() string"},
+	"Option.HasEnumAll":            {Signature: "HasEnumAll() bool"},
+	"Option.IsAlias":               {Signature: "IsAlias() bool"},
+	"Option.IsApiHidden":           {Signature: "IsApiHidden() bool"},
+	"Option.IsArray":               {Signature: "IsArray() bool"},
+	"Option.IsBool":                {Signature: "IsBool() bool"},
+	"Option.IsConfig":              {Signature: "IsConfig() bool"},
+	"Option.IsConfigurableAddr":    {Signature: "IsConfigurableAddr() bool"},
+	"Option.IsCrud":                {Signature: "IsCrud() bool"},
+	"Option.IsDeprecated":          {Signature: "IsDeprecated() bool"},
+	"Option.IsEnum":                {Signature: "IsEnum() bool"},
+	"Option.IsFlag":                {Signature: "IsFlag() bool"},
+	"Option.IsFlagAlias":           {Signature: "IsFlagAlias() bool"},
+	"Option.IsFloat":               {Signature: "IsFloat() bool"},
+	"Option.IsHidden":              {Signature: "IsHidden() bool"},
+	"Option.IsMode":                {Signature: "IsMode() bool"},
+	"Option.IsNullDefault":         {Signature: "IsNullDefault() bool"},
+	"Option.IsNullDefault2":        {Signature: "IsNullDefault2() bool"},
+	"Option.IsPositional":          {Signature: "IsPositional() bool"},
+	"Option.IsRequired":            {Signature: "IsRequired() bool"},
+	"Option.IsSpecialAddr":         {Signature: "IsSpecialAddr() bool"},
+	"Option.IsStringLike":          {Signature: "IsStringLike() bool"},
+	"Option.IsVisible":             {Signature: "IsVisible() bool"},
+	"Option.IsVisibleDocs":         {Signature: "IsVisibleDocs() bool"},
+	"Option.JsonTag":               {Signature: "JsonTag() string"},
+	"Option.Lower":                 {Signature: "Lower() string"},
+	"Option.ModeType":              {Signature: "ModeType() string"},
+	"Option.OptField":              {Signature: "OptField() string"},
+	"Option.Pos":                   {Signature: "Pos() Position", Doc: "Pos returns where the option is defined."},
+	"Option.PreSwitch":             {Signature: "PreSwitch() string"},
+	"Option.PyHotKey":              {Signature: "PyHotKey() string"},
+	"Option.RequestOpt":            {Signature: "RequestOpt() string"},
+	"Option.SdkCoreType":           {Signature: "SdkCoreType() string"},
+	"Option.SdkEndpoint":           {Signature: "SdkEndpoint() string"},
+	"Option.SdkIsPublic":           {Signature: "SdkIsPublic() bool"},
+	"Option.SomeCases":             {Signature: "SomeCases() string"},
+	"Option.String":                {Signature: "String() string"},
+	"Option.Stripped":              {Signature: "Stripped() string"},
+	"Option.TestLog":               {Signature: "TestLog() string"},
+	"Option.ToolAssignment":        {Signature: "ToolAssignment() string"},
+	"Option.ToolParameters":        {Signature: "ToolParameters(forSdk bool) string"},
+	"Option.ToolTurd":              {Signature: "ToolTurd() string"},
+	"Option.TsEnumTypes":           {Signature: "TsEnumTypes() []string"},
+	"Option.TsOption":              {Signature: "TsOption() string"},
+	"Option.Validate":              {Signature: "Validate() bool"},
+	"Store.CountOptions":           {Signature: "CountOptions() string", Doc: "sdk.{{$val}}Options"},
+	"Store.GetMapKey":              {Signature: "GetMapKey() string"},
+	"Store.HasActions":             {Signature: "HasActions(facets []Facet) bool"},
+	"Store.Members":                {Signature: "Members() []Member"},
+	"Store.NMembers":               {Signature: "NMembers() int"},
+	"Store.NeedsBuckets":           {Signature: "NeedsBuckets() bool"},
+	"Store.NeedsCalcs":             {Signature: "NeedsCalcs() bool"},
+	"Store.UseMapKey":              {Signature: "UseMapKey() bool"},
+	"Structure.Addresses":          {Signature: "Addresses() []string"},
+	"Structure.AllActions":         {Signature: "AllActions() []string"},
+	"Structure.CacheIdStr":         {Signature: "CacheIdStr() string"},
+	"Structure.CacheLoc":           {Signature: "CacheLoc() string", Doc: "CacheLoc returns the name of the cache the structure is stored in. A structure that extends another one cached by the same fields shares its cache."},
+	"Structure.CalcMembers":        {Signature: "CalcMembers() []string"},
+	"Structure.ClassOrClassGroup":  {Signature: "ClassOrClassGroup() string"},
+	"Structure.DocSortOrder":       {Signature: "DocSortOrder() []Member"},
+	"Structure.EmbedName":          {Signature: "EmbedName() string"},
+	"Structure.EmbedType":          {Signature: "EmbedType() string"},
+	"Structure.FacetByName":        {Signature: "FacetByName(facet string) *Facet"},
+	"Structure.FacetsStr":          {Signature: "FacetsStr() string"},
+	"Structure.GroupName":          {Signature: "GroupName() string"},
+	"Structure.HandlerStrs":        {Signature: "HandlerStrs() string"},
+	"Structure.Handlers":           {Signature: "Handlers() string"},
+	"Structure.Handlers_inner":     {Signature: "Handlers_inner() string"},
+	"Structure.HasAddresses":       {Signature: "HasAddresses() bool"},
+	"Structure.HasAutoname":        {Signature: "HasAutoname() bool"},
+	"Structure.HasCrud":            {Signature: "HasCrud() bool"},
+	"Structure.HasCrudActions":     {Signature: "HasCrudActions() bool"},
+	"Structure.HasCustomFacet":     {Signature: "HasCustomFacet() bool"},
+	"Structure.HasCustomPanel":     {Signature: "HasCustomPanel() bool"},
+	"Structure.HasCustomRenderers": {Signature: "HasCustomRenderers() bool"},
+	"Structure.HasDelete":          {Signature: "HasDelete() bool"},
+	"Structure.HasDynamicFacets":   {Signature: "HasDynamicFacets() bool"},
+	"Structure.HasFacets":          {Signature: "HasFacets() bool"},
+	"Structure.HasForms":           {Signature: "HasForms() bool"},
+	"Structure.HasNotes":           {Signature: "HasNotes() bool"},
+	"Structure.HasRowActions":      {Signature: "HasRowActions() bool"},
+	"Structure.HasSorts":           {Signature: "HasSorts() bool"},
+	"Structure.HasTimestamp":       {Signature: "HasTimestamp() bool"},
+	"Structure.HasTsTypesTypes":    {Signature: "HasTsTypesTypes() bool"},
+	"Structure.HasUpdate":          {Signature: "HasUpdate() bool"},
+	"Structure.HeaderActionsBe":    {Signature: "HeaderActionsBe(facet string) string"},
+	"Structure.IsCachable":         {Signature: "IsCachable() bool"},
+	"Structure.IsCacheAsGroup":     {Signature: "IsCacheAsGroup() bool"},
+	"Structure.IsFilenameCache":    {Signature: "IsFilenameCache() bool"},
+	"Structure.IsMarshalOnly":      {Signature: "IsMarshalOnly() bool"},
+	"Structure.ItemFullType":       {Signature: "ItemFullType() string"},
+	"Structure.ItemName":           {Signature: "ItemName() string"},
+	"Structure.ItemType":           {Signature: "ItemType() string"},
+	"Structure.ModelIntro":         {Signature: "ModelIntro() string"},
+	"Structure.ModelMembers":       {Signature: "ModelMembers() string"},
+	"Structure.ModelNotes":         {Signature: "ModelNotes() string"},
+	"Structure.ModelProducers":     {Signature: "ModelProducers() string"},
+	"Structure.Name":               {Signature: "Name() string"},
+	"Structure.Needs":              {Signature: "Needs(which string) bool"},
+	"Structure.NeedsAddress":       {Signature: "NeedsAddress() bool"},
+	"Structure.NeedsStatement":     {Signature: "NeedsStatement() bool"},
+	"Structure.Num":                {Signature: "Num() int"},
+	"Structure.Pos":                {Signature: "Pos() Position", Doc: "Pos returns where the structure is defined (its TOML file, or its line in base-types.csv)."},
+	"Structure.ProcessFile":        {Signature: "ProcessFile(sourceIn, group, reason string) (err error)", Doc: "ProcessFile processes a single file, applying the template to it and writing the result to the destination."},
+	"Structure.RemoveCallback":     {Signature: "RemoveCallback() string"},
+	"Structure.RowActionsBe":       {Signature: "RowActionsBe(facet string) string"},
+	"Structure.RowActionsFe":       {Signature: "RowActionsFe() string"},
+	"Structure.SortFields":         {Signature: "SortFields() string"},
+	"Structure.SortString":         {Signature: "SortString() string"},
+	"Structure.SortSwitches":       {Signature: "SortSwitches() string"},
+	"Structure.Sorts2":             {Signature: "Sorts2() string", Doc: "Sorts2 for tag {{.Sorts2}}"},
+	"Structure.SortsInstance":      {Signature: "SortsInstance() string"},
+	"Structure.Stores":             {Signature: "Stores() []Store"},
+	"Structure.String":             {Signature: "String() string"},
+	"Structure.TsTypeMembers":      {Signature: "TsTypeMembers() string"},
+	"Structure.TsTypesTypes":       {Signature: "TsTypesTypes() string"},
+	"Structure.UiHotKey":           {Signature: "UiHotKey() string"},
+	"Structure.UiRouteName":        {Signature: "UiRouteName() string"},
+	"Structure.UiRouteNum":         {Signature: "UiRouteNum() uint64"},
+	"Structure.UniqueActions":      {Signature: "UniqueActions() []string", Doc: "returns a list of unique actions from all the facets sorted alphabetically"},
+	"Structure.Validate":           {Signature: "Validate() bool"},
+	"Structure.Wants":              {Signature: "Wants(which string) bool"},
+}
@@ -0,0 +1,41 @@
+package types
+
+import "testing"
+
+func TestGetReference(t *testing.T) {
+	ref, err := GetReference()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	funcs := map[string]ReferenceEntry{}
+	for _, f := range ref.Functions {
+		funcs[f.Name] = f
+	}
+	if len(funcs) != len(getFuncMap()) {
+		t.Errorf("expected %d functions, got %d", len(getFuncMap()), len(funcs))
+	}
+	if f := funcs["add"]; f.Signature != "add(a, b int) int" || f.Doc != "Returns `a + b`." {
+		t.Errorf("unexpected entry for add: %+v", f)
+	}
+
+	var command *ReceiverReference
+	for i := range ref.Receivers {
+		if ref.Receivers[i].Name == "Command" {
+			command = &ref.Receivers[i]
+		}
+	}
+	if command == nil {
+		t.Fatal("Command is missing from the reference")
+	}
+	methods := map[string]ReferenceEntry{}
+	for _, m := range command.Methods {
+		methods[m.Name] = m
+	}
+	if m, ok := methods["GroupIntro"]; !ok || m.Signature != "GroupIntro(reason string) string" || m.Type != "string" {
+		t.Errorf("unexpected entry for GroupIntro: %+v", m)
+	}
+	if m := methods["EnsConvert1"]; m.Doc != "For tag {{.EnsConvert1}}" {
+		t.Errorf("unexpected doc for EnsConvert1: %q", m.Doc)
+	}
+}
@@ -0,0 +1,122 @@
+package types
+
+import (
+	"strings"
+)
+
+// checkReferences cross-checks the names models and routes use to refer to each other.
+// A name that refers to nothing is an error, as is a route returning a model that does
+// not list the route in its produced_by. A contains (or contained_by) that the other
+// model does not reciprocate is a warning.
+//
+// The contains and contained_by lists only document how the models nest. No generated
+// code depends on the two sides agreeing, and existing models (Function and Log, for
+// example) list the relationship on one side only, so an asymmetry does not fail the run.
+func (cb *CodeBase) checkReferences() {
+	routes := make(map[string]bool, len(cb.Commands))
+	for _, c := range cb.Commands {
+		if c.Route != "" {
+			routes[c.Route] = true
+		}
+	}
+
+	for i := range cb.Structures {
+		st := &cb.Structures[i]
+		for _, route := range st.Producers {
+			if route != "" && !routes[route] {
+				reportError(st.pos.withKey("settings.produced_by"), "structure %s is produced by %s, which is not a route", st.Class, route)
+			}
+		}
+
+		for _, name := range splitNames(st.ContainedBy) {
+			if other := cb.findStructure(Lower(name)); other == nil {
+				reportError(st.pos.withKey("settings.contained_by"), "structure %s is contained by %s, which is not a model", st.Class, name)
+			} else if !other.lists(other.Contains, st) {
+				reportWarning(st.pos.withKey("settings.contained_by"), "structure %s is contained by %s, which does not list it in contains", st.Class, other.Class)
+			}
+		}
+
+		for _, name := range splitNames(st.Contains) {
+			if other := cb.findStructure(Lower(name)); other == nil {
+				if !isKnownType(name) {
+					reportError(st.pos.withKey("settings.contains"), "structure %s contains %s, which is not a model", st.Class, name)
+				}
+			} else if !other.lists(other.ContainedBy, st) {
+				reportWarning(st.pos.withKey("settings.contains"), "structure %s contains %s, which does not list it in contained_by", st.Class, other.Class)
+			}
+		}
+
+		if st.Parent != "" && cb.findStructure(Lower(st.Parent)) == nil {
+			reportError(st.pos.withKey("settings.parent"), "parent %s of %s not found", st.Parent, st.Class)
+		}
+
+		for _, ch := range st.ChildTabs {
+			if ch == "" {
+				continue
+			}
+			found := false
+			for _, other := range cb.Structures {
+				if Plural(other.Name()) == ch {
+					found = true
+					break
+				}
+			}
+			if !found {
+				reportError(st.pos.withKey("settings.children"), "child %s of %s not found", ch, st.Class)
+			}
+		}
+	}
+
+	reported := map[string]bool{}
+	for _, c := range cb.Commands {
+		for _, op := range c.Options {
+			// A mode's return type depends on the mode chosen (see EnumTypes)
+			if op.ReturnType == "" || op.ReturnType == "mode" {
+				continue
+			}
+			if st := cb.findStructure(Lower(op.ReturnType)); st != nil {
+				produced := false
+				for _, route := range st.Producers {
+					produced = produced || route == c.Route
+				}
+				if !produced && !reported[c.Route+":"+st.Class] {
+					reported[c.Route+":"+st.Class] = true
+					reportError(op.pos.withKey("return_type"), "route %s returns %s, which does not list %s in produced_by", c.Route, st.Class, c.Route)
+				}
+			} else if !isKnownType(op.ReturnType) && op.ReturnType != "bool" {
+				reportError(op.pos.withKey("return_type"), "route %s returns %s, which is neither a model nor a known type", c.Route, op.ReturnType)
+			}
+		}
+	}
+}
+
+// lists returns true if the comma separated names in field include other.
+func (s *Structure) lists(field string, other *Structure) bool {
+	for _, name := range splitNames(field) {
+		if strings.EqualFold(name, other.Class) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitNames splits a comma separated list of names, dropping empty ones.
+func splitNames(names string) []string {
+	ret := []string{}
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			ret = append(ret, name)
+		}
+	}
+	return ret
+}
+
+// isKnownType returns true if typ (in any case) is one of the knownTypes.
+func isKnownType(typ string) bool {
+	for known := range knownTypes {
+		if strings.EqualFold(known, typ) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package types
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestCheckReferences(t *testing.T) {
+	ResetDiagnostics()
+	defer ResetDiagnostics()
+
+	model := func(class, producedBy, containedBy, contains string) Structure {
+		st := Structure{Class: class, ProducedBy: producedBy, ContainedBy: containedBy, Contains: contains}
+		_, _ = readStructure(&st, nil)
+		st.pos = Position{File: class + ".toml"}
+		return st
+	}
+	cb := CodeBase{
+		Structures: []Structure{
+			model("Block", "blocks", "", "transaction, withdrawal, AddrRecord"),
+			model("Transaction", "blocks, transactions", "block", ""),
+			model("Log", "logs", "receipt", ""),
+			model("Withdrawal", "blocks", "", ""),
+		},
+		Commands: []Command{
+			{Route: "blocks", Options: []Option{{Route: "blocks", ReturnType: "block"}}},
+			{Route: "transactions", Options: []Option{{Route: "transactions", ReturnType: "transaction"}, {Route: "transactions", ReturnType: "log"}, {Route: "transactions", ReturnType: "log"}}},
+			{Route: "config", Options: []Option{{Route: "config", ReturnType: "config"}, {Route: "config", ReturnType: "bool"}, {Route: "config", ReturnType: "settings"}}},
+		},
+	}
+	cb.checkReferences()
+
+	got := []string{}
+	for _, d := range Diagnostics() {
+		got = append(got, string(d.Severity)+": "+d.Message)
+	}
+	sort.Strings(got)
+	want := []string{
+		"error: route config returns settings, which is neither a model nor a known type",
+		"error: route transactions returns Log, which does not list transactions in produced_by",
+		"error: structure Log is contained by receipt, which is not a model",
+		"error: structure Log is produced by logs, which is not a route",
+		"warning: structure Block contains Withdrawal, which does not list it in contained_by",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d:\n%v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("diagnostic %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,421 @@
+package types
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
+)
+
+// Scaffold records the files created or changed by NewType and NewRoute so that the
+// change can be undone if the result does not validate.
+type Scaffold struct {
+	Created  []string          `json:"created"`
+	Modified []string          `json:"modified,omitempty"`
+	original map[string]string `json:"-"`
+}
+
+func (s *Scaffold) create(path, contents string) error {
+	if file.FileExists(path) {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if err := file.EstablishFolder(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		return err
+	}
+	s.Created = append(s.Created, path)
+	return nil
+}
+
+func (s *Scaffold) modify(path, contents string) error {
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if s.original == nil {
+		s.original = map[string]string{}
+	}
+	if _, ok := s.original[path]; !ok {
+		s.original[path] = string(original)
+		s.Modified = append(s.Modified, path)
+	}
+	return os.WriteFile(path, []byte(contents), 0644)
+}
+
+// Undo removes the files the scaffold created and restores the ones it changed.
+func (s *Scaffold) Undo() error {
+	for _, path := range s.Created {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	for path, contents := range s.original {
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewTypeOptions describes a data model for NewType.
+type NewTypeOptions struct {
+	Name        string
+	Group       string   // the doc_group with or without its number (05-Other or Other)
+	Description string   // the doc_descr
+	ProducedBy  string   // comma separated routes
+	Fields      []string // name:type pairs in order
+}
+
+// NewType creates the class definition, fields, and model intro for a new data model. The
+// doc_route is the next free one in the group.
+func (cb *CodeBase) NewType(opts NewTypeOptions) (*Scaffold, error) {
+	if !regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`).MatchString(opts.Name) {
+		return nil, fmt.Errorf("invalid type name '%s'", opts.Name)
+	}
+	class := FirstUpper(opts.Name)
+	for _, st := range cb.Structures {
+		if strings.EqualFold(st.Class, class) {
+			return nil, fmt.Errorf("type %s already exists", st.Class)
+		}
+	}
+	if len(opts.Fields) == 0 {
+		return nil, fmt.Errorf("type %s needs at least one field", class)
+	}
+
+	docGroup, err := cb.findDocGroup(opts.Group)
+	if err != nil {
+		return nil, err
+	}
+	docRoute := fmt.Sprintf("%d-%s", cb.nextDocRoute(docGroup), FirstLower(class))
+
+	descr := opts.Description
+	if descr == "" {
+		descr = "a " + Lower(class)
+	}
+
+	rows := [][]string{}
+	for i, field := range opts.Fields {
+		parts := strings.Split(field, ":")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid field '%s' (expected name:type)", field)
+		}
+		rows = append(rows, []string{parts[0], parts[1], "", "", strconv.Itoa(i + 1), csvEscape("the " + parts[0] + " of the " + Lower(class))})
+	}
+
+	toml := []string{
+		"[settings]",
+		"    class = " + tomlString(class),
+		"    doc_group = " + tomlString(docGroup),
+		"    doc_descr = " + tomlString(descr),
+		"    doc_route = " + tomlString(docRoute),
+		"    attributes = " + tomlString(""),
+		"    produced_by = " + tomlString(opts.ProducedBy),
+	}
+
+	thePath, err := getTemplatePath()
+	if err != nil {
+		return nil, err
+	}
+	classDefs := filepath.Join(thePath, "classDefinitions")
+	s := &Scaffold{}
+	for _, f := range []struct{ path, contents string }{
+		{filepath.Join(classDefs, Lower(class)+".toml"), strings.Join(toml, "\n") + "\n"},
+		{filepath.Join(classDefs, "fields", Lower(class)+".csv"), fieldsCsv(rows)},
+		{filepath.Join(thePath, "model-intros", CamelCase(class)+".md"), FirstUpper(descr) + ".\n"},
+	} {
+		if err := s.create(f.path, f.contents); err != nil {
+			_ = s.Undo()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// tomlString quotes s as a TOML basic string.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// csvEscape escapes the commas in a field of the csv files as the existing files do.
+func csvEscape(s string) string {
+	return strings.ReplaceAll(s, ",", "&#44;")
+}
+
+// fieldsCsv formats the rows of a fields file with aligned columns as the existing
+// files are.
+func fieldsCsv(rows [][]string) string {
+	header := []string{"name", "type", "strDefault", "attributes", "docOrder", "description"}
+	wids := widths(header, rows)
+	line := func(row []string) string {
+		fields := []string{}
+		for i, field := range row {
+			switch {
+			case i == len(row)-1:
+				// the description is not padded
+			case i == 4 && field != "docOrder":
+				field = strings.Repeat(" ", wids[i]-len(field)) + field
+			default:
+				field = Pad(field, wids[i])
+			}
+			fields = append(fields, field)
+		}
+		return strings.Join(fields, " ,")
+	}
+	ret := []string{line(header)}
+	for _, row := range rows {
+		ret = append(ret, line(row))
+	}
+	return strings.Join(ret, "\n") + "\n"
+}
+
+// findDocGroup returns the doc_group (for example, 05-Other) matching group which may
+// be given with or without its number.
+func (cb *CodeBase) findDocGroup(group string) (string, error) {
+	known := map[string]bool{}
+	for _, st := range cb.Structures {
+		known[st.DocGroup] = true
+	}
+	names := []string{}
+	for docGroup := range known {
+		name := docGroup
+		if parts := strings.SplitN(docGroup, "-", 2); len(parts) == 2 {
+			name = parts[1]
+		}
+		if strings.EqualFold(docGroup, group) || strings.EqualFold(name, group) {
+			return docGroup, nil
+		}
+		names = append(names, docGroup)
+	}
+	sort.Strings(names)
+	return "", fmt.Errorf("unknown group '%s' (expected one of %s)", group, strings.Join(names, ", "))
+}
+
+// nextDocRoute returns the number following the highest doc_route in the group.
+func (cb *CodeBase) nextDocRoute(docGroup string) int {
+	groupNum, _ := strconv.Atoi(strings.SplitN(docGroup, "-", 2)[0])
+	last := groupNum * 100
+	for _, st := range cb.Structures {
+		if st.DocGroup != docGroup {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.SplitN(st.DocRoute, "-", 2)[0]); err == nil && n > last {
+			last = n
+		}
+	}
+	return last + 3
+}
+
+// NewRouteOptions describes a chifra command for NewRoute.
+type NewRouteOptions struct {
+	Route       string
+	Group       string // the group as it appears in cmd-line-options.csv (for example, Chain Data)
+	Folder      string // apps or tools
+	Tool        string
+	Summary     string
+	Description string
+	Returns     string   // the type produced by the first option
+	Options     []string // name:kind[:data_type] where kind is positional, flag, or switch
+}
+
+// NewRoute adds the rows for a new command to cmd-line-options.csv at the end of its group
+// and creates its readme intro. The command's num is the next free one in the group.
+func (cb *CodeBase) NewRoute(opts NewRouteOptions) (*Scaffold, error) {
+	if !regexp.MustCompile(`^[a-z][a-z0-9]*$`).MatchString(opts.Route) {
+		return nil, fmt.Errorf("invalid route '%s' (use lower case letters and digits)", opts.Route)
+	}
+	for _, c := range cb.Commands {
+		if c.Route == opts.Route {
+			return nil, fmt.Errorf("route %s already exists", opts.Route)
+		}
+	}
+
+	var group *Command
+	names := []string{}
+	for i := range cb.Commands {
+		if cb.Commands[i].Route == "" {
+			names = append(names, cb.Commands[i].Group)
+			if strings.EqualFold(cb.Commands[i].Group, opts.Group) {
+				group = &cb.Commands[i]
+			}
+		}
+	}
+	if group == nil {
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown group '%s' (expected one of %s)", opts.Group, strings.Join(names, ", "))
+	}
+
+	num := group.Num + 1000
+	for _, c := range cb.Commands {
+		if c.Route != "" && c.Group == group.Group && c.Num >= num {
+			num = (c.Num/1000)*1000 + 1000
+		}
+	}
+	if num/10000 != group.Num/10000 {
+		return nil, fmt.Errorf("group %s has no free command numbers", group.Group)
+	}
+
+	folder := opts.Folder
+	if folder == "" {
+		folder = "tools"
+	}
+	tool := opts.Tool
+	if tool == "" {
+		tool = opts.Route
+	}
+	summary := csvEscape(opts.Summary)
+	if summary == "" {
+		summary = FirstUpper(opts.Route)
+	}
+	descr := csvEscape(opts.Description)
+	if descr == "" {
+		descr = summary + "."
+	}
+
+	usage := []string{"[flags]"}
+	options := [][]string{}
+	handled := false
+	for i, o := range opts.Options {
+		parts := strings.Split(o, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid option '%s' (expected name:kind[:data_type])", o)
+		}
+		name, kind, dataType := parts[0], parts[1], ""
+		if len(parts) == 3 {
+			dataType = parts[2]
+		}
+		attributes := "visible|docs"
+		switch kind {
+		case "positional":
+			if dataType == "" {
+				dataType = "list<string>"
+			}
+			attributes = "required|" + attributes
+			usage = append(usage, "<"+name+"> ["+name+"...]")
+		case "flag":
+			if dataType == "" {
+				dataType = "<string>"
+			}
+		case "switch":
+			dataType = "<boolean>"
+		default:
+			return nil, fmt.Errorf("invalid option kind '%s' in '%s' (expected positional, flag, or switch)", kind, o)
+		}
+		// The first option handles the command (and produces what it returns)
+		handler, returns := "", ""
+		if !handled {
+			handler, returns, handled = "1", opts.Returns, true
+		}
+		options = append(options, []string{
+			strconv.Itoa(num + 20 + 10*i), folder, group.Group, opts.Route, tool, name, "", "",
+			attributes, handler, kind, dataType, returns, "", "", "", csvEscape("the " + strings.ReplaceAll(name, "_", " ") + " option"),
+		})
+	}
+
+	rows := [][]string{{
+		strconv.Itoa(num), folder, group.Group, opts.Route, tool, "", "", "",
+		"visible|docs", "", "command", "", "", summary, strings.Join(usage, " "), "default|", descr,
+	}}
+	rows = append(rows, options...)
+
+	thePath, err := getTemplatePath()
+	if err != nil {
+		return nil, err
+	}
+	csvPath := filepath.Join(thePath, "cmd-line-options.csv")
+	lines := file.AsciiFileToLines(csvPath)
+
+	// Insert after the last row of the group (before the separator that precedes the next one)
+	at := len(lines)
+	for i, line := range lines {
+		fields := strings.Split(line, ",")
+		if len(fields) > 2 && !strings.HasPrefix(line, "#") {
+			if n, err := strconv.Atoi(fields[0]); err == nil && n > group.Num && fields[2] != group.Group {
+				at = i
+				break
+			}
+		}
+	}
+	for at > 0 && strings.HasPrefix(lines[at-1], "#") {
+		at--
+	}
+
+	added := []string{"#"}
+	for _, row := range rows {
+		added = append(added, strings.Join(row, ","))
+	}
+	lines = append(lines[:at], append(added, lines[at:]...)...)
+
+	s := &Scaffold{}
+	if err := s.modify(csvPath, strings.Join(lines, "\n")+"\n"); err != nil {
+		return nil, err
+	}
+	// A route that returns a model must be listed in the model's produced_by
+	if st := cb.findStructure(Lower(opts.Returns)); st != nil && !slices.Contains(splitNames(st.ProducedBy), opts.Route) {
+		contents, err := os.ReadFile(st.pos.File)
+		if err == nil {
+			err = s.modify(st.pos.File, addProducer(string(contents), opts.Route))
+		}
+		if err != nil {
+			_ = s.Undo()
+			return nil, err
+		}
+	}
+	intro := strings.TrimSuffix(strings.ReplaceAll(descr, "&#44;", ","), ".") + ".\n"
+	if err := s.create(filepath.Join(thePath, "readme-intros", opts.Route+".md"), intro); err != nil {
+		_ = s.Undo()
+		return nil, err
+	}
+	return s, nil
+}
+
+// addProducer adds the route to the produced_by setting of a class definition, adding the
+// setting after the class if there is none.
+func addProducer(contents, route string) string {
+	lines := strings.Split(contents, "\n")
+	producedBy := regexp.MustCompile(`^(\s*)produced_by\s*=\s*"(.*)"\s*$`)
+	for i, line := range lines {
+		if m := producedBy.FindStringSubmatch(line); m != nil {
+			routes := append(splitNames(m[2]), route)
+			lines[i] = m[1] + "produced_by = " + tomlString(strings.Join(routes, ", "))
+			return strings.Join(lines, "\n")
+		}
+	}
+	at, indent := 0, "    "
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "[settings]" {
+			at = i + 1
+		} else if trimmed := strings.TrimLeft(line, " \t"); strings.HasPrefix(trimmed, "class") && at > 0 {
+			at, indent = i+1, line[:len(line)-len(trimmed)]
+			break
+		}
+	}
+	added := indent + "produced_by = " + tomlString(route)
+	lines = append(lines[:at], append([]string{added}, lines[at:]...)...)
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,212 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
+)
+
+func TestNewType(t *testing.T) {
+	cb, templates := loadTemplatesCopy(t)
+
+	if _, err := cb.NewType(NewTypeOptions{Name: "block", Group: "Other", Fields: []string{"a:string"}}); err == nil || !strings.Contains(err.Error(), "type Block already exists") {
+		t.Errorf("expected an existing type to be rejected, got %v", err)
+	}
+	if _, err := cb.NewType(NewTypeOptions{Name: "widget", Group: "Nowhere", Fields: []string{"a:string"}}); err == nil || !strings.Contains(err.Error(), "unknown group 'Nowhere'") {
+		t.Errorf("expected an unknown group to be rejected, got %v", err)
+	}
+
+	scaffold, err := cb.NewType(NewTypeOptions{
+		Name:        "widget",
+		Group:       "Other",
+		Description: `a "widget" \ gadget`,
+		ProducedBy:  "slurp",
+		Fields:      []string{"name:string", "value:uint64"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scaffold.Created) != 3 || len(scaffold.Modified) != 0 {
+		t.Errorf("expected three new files, got %+v", scaffold)
+	}
+
+	// The validation run sees the new type
+	reloaded, err := LoadCodebase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var widget *Structure
+	for i := range reloaded.Structures {
+		if reloaded.Structures[i].Class == "Widget" {
+			widget = &reloaded.Structures[i]
+		}
+	}
+	if widget == nil {
+		t.Fatal("the new type did not load")
+	}
+	if widget.DocGroup != "05-Other" || widget.DocRoute != "527-widget" || len(widget.Members) != 2 {
+		t.Errorf("got doc_group %s, doc_route %s, and %d members", widget.DocGroup, widget.DocRoute, len(widget.Members))
+	}
+	if widget.DocDescr != `a "widget" \ gadget` {
+		t.Errorf("got doc_descr %q", widget.DocDescr)
+	}
+
+	if err := scaffold.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range scaffold.Created {
+		if file.FileExists(path) {
+			t.Errorf("%s was not removed", path)
+		}
+	}
+
+	// A type that does not validate can be undone
+	scaffold, err = cb.NewType(NewTypeOptions{Name: "widget", Group: "Other", ProducedBy: "nosuchroute", Fields: []string{"a:string"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ResetDiagnostics()
+	if _, err := LoadCodebase(); err == nil {
+		t.Error("expected a type produced by an unknown route not to validate")
+	}
+	if err := scaffold.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	ResetDiagnostics()
+	if _, err := LoadCodebase(); err != nil {
+		t.Errorf("the codebase does not load after the undo: %v", err)
+	}
+	if file.FileExists(filepath.Join(templates, "classDefinitions", "widget.toml")) {
+		t.Error("the class definition was not removed")
+	}
+}
+
+func TestNewRoute(t *testing.T) {
+	cb, templates := loadTemplatesCopy(t)
+	csvPath := filepath.Join(templates, "cmd-line-options.csv")
+	original, err := os.ReadFile(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cb.NewRoute(NewRouteOptions{Route: "blocks", Group: "Chain Data"}); err == nil || !strings.Contains(err.Error(), "route blocks already exists") {
+		t.Errorf("expected an existing route to be rejected, got %v", err)
+	}
+
+	undo := func(scaffold *Scaffold) {
+		t.Helper()
+		if err := scaffold.Undo(); err != nil {
+			t.Fatal(err)
+		}
+		restored, err := os.ReadFile(csvPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(restored) != string(original) {
+			t.Error("cmd-line-options.csv was not restored byte for byte")
+		}
+		if file.FileExists(filepath.Join(templates, "readme-intros", "gadgets.md")) {
+			t.Error("the intro was not removed")
+		}
+	}
+
+	options := NewRouteOptions{
+		Route:   "gadgets",
+		Group:   "Chain State",
+		Options: []string{"addrs:positional:list<addr>", "fmt:flag", "raw:switch"},
+	}
+	scaffold, err := cb.NewRoute(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scaffold.Modified) != 1 || scaffold.Modified[0] != csvPath || len(scaffold.Created) != 1 {
+		t.Errorf("expected cmd-line-options.csv to change and the intro to be created, got %+v", scaffold)
+	}
+
+	// The new rows follow the group's last command
+	lines := file.AsciiFileToLines(csvPath)
+	last, first := -1, -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "33") {
+			last = i
+		}
+		if first == -1 && strings.HasPrefix(line, "34000,") {
+			first = i
+		}
+	}
+	if first == -1 || first != last+2 || lines[first-1] != "#" {
+		t.Errorf("the new command is at line %d, the group's last row at line %d", first+1, last+1)
+	}
+
+	// The validation run sees the new rows
+	reloaded, err := LoadCodebase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gadgets *Command
+	for i := range reloaded.Commands {
+		if reloaded.Commands[i].Route == "gadgets" {
+			gadgets = &reloaded.Commands[i]
+		}
+	}
+	if gadgets == nil {
+		t.Fatal("the new route did not load")
+	}
+	if gadgets.Num != 34000 || gadgets.Group != "Chain State" || len(gadgets.Options) != 3 {
+		t.Errorf("got num %d, group %s, and %d options", gadgets.Num, gadgets.Group, len(gadgets.Options))
+	}
+	undo(scaffold)
+
+	// A route returning a model is added to the model's produced_by, and commas in the
+	// summary and description are escaped
+	statePath := filepath.Join(templates, "classDefinitions", "state.toml")
+	originalState, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	options.Returns = "state"
+	options.Summary = "Gadgets, gizmos"
+	options.Description = "Report gadgets, gizmos, and widgets."
+	if scaffold, err = cb.NewRoute(options); err != nil {
+		t.Fatal(err)
+	}
+	if len(scaffold.Modified) != 2 || scaffold.Modified[1] != statePath {
+		t.Errorf("expected state.toml to change, got %+v", scaffold)
+	}
+	ResetDiagnostics()
+	if reloaded, err = LoadCodebase(); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range reloaded.Commands {
+		if c.Route == "gadgets" && (c.Summary != "Gadgets, gizmos" || c.Description != options.Description) {
+			t.Errorf("got summary %q and description %q", c.Summary, c.Description)
+		}
+	}
+	if state := reloaded.findStructure("state"); state == nil || !slices.Contains(splitNames(state.ProducedBy), "gadgets") {
+		t.Error("the route was not added to State's produced_by")
+	}
+	undo(scaffold)
+	if restored, _ := os.ReadFile(statePath); string(restored) != string(originalState) {
+		t.Error("state.toml was not restored byte for byte")
+	}
+}
+
+func TestAddProducer(t *testing.T) {
+	tests := []struct {
+		contents string
+		want     string
+	}{
+		{"[settings]\n    class = \"State\"\n    produced_by = \"state, tokens\"\n", "[settings]\n    class = \"State\"\n    produced_by = \"state, tokens, gadgets\"\n"},
+		{"[settings]\n    class = \"State\"\n    produced_by = \"\"\n", "[settings]\n    class = \"State\"\n    produced_by = \"gadgets\"\n"},
+		{"[settings]\n  class = \"State\"\n  doc_group = \"02-Chain Data\"\n", "[settings]\n  class = \"State\"\n  produced_by = \"gadgets\"\n  doc_group = \"02-Chain Data\"\n"},
+	}
+	for _, tt := range tests {
+		if got := addProducer(tt.contents, "gadgets"); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
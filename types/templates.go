@@ -5,31 +5,66 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 	"text/template"
-
-	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/logger"
 )
 
-var codebaseCache map[string]*template.Template
+var (
+	codebaseCache map[string]*template.Template
+	cacheMutex    sync.RWMutex
+)
 
 func init() {
 	codebaseCache = make(map[string]*template.Template)
 }
 
+// ResetTemplateCache forgets every parsed template so that changes to the templates on
+// disk are picked up by the next call to Generate.
+func ResetTemplateCache() {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	codebaseCache = make(map[string]*template.Template)
+}
+
+// getCachedTemplate returns the parsed template, parsing and caching it if needed. It is
+// safe for concurrent use. Parsed templates may be executed concurrently.
+func getCachedTemplate(tmplName, tmplCode string) (*template.Template, error) {
+	cacheMutex.RLock()
+	tmpl := codebaseCache[tmplName]
+	cacheMutex.RUnlock()
+	if tmpl != nil {
+		return tmpl, nil
+	}
+
+	tmpl, err := template.New(tmplName).Funcs(getFuncMap()).Parse(tmplCode)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	if existing := codebaseCache[tmplName]; existing != nil {
+		return existing, nil
+	}
+	codebaseCache[tmplName] = tmpl
+	return tmpl, nil
+}
+
+// executeTemplate applies the template to the receiver. On failure, it panics with an
+// error. If the failing template was invoked from within another template, text/template
+// turns the panic into an execution error of the outer template. At the top level, the
+// panic is recovered (see recoverError) and returned from ProcessFile.
 func executeTemplate(receiver any, tmplPrefix, name, tmplCode string) string {
 	tmplName := tmplPrefix + " " + name
 
-	if codebaseCache[tmplName] == nil {
-		tmpl, err := template.New(tmplName).Funcs(getFuncMap()).Parse(tmplCode)
-		if err != nil {
-			logger.Fatalf("parsing template failed: %v", err)
-		}
-		codebaseCache[tmplName] = template.Must(tmpl, nil)
+	tmpl, err := getCachedTemplate(tmplName, tmplCode)
+	if err != nil {
+		panic(fmt.Errorf("parsing template failed: %w", err))
 	}
 
 	var tplBuffer bytes.Buffer
-	if err := codebaseCache[tmplName].Execute(&tplBuffer, receiver); err != nil {
-		logger.Fatalf("executing template failed: %v", err)
+	if err := tmpl.Execute(&tplBuffer, receiver); err != nil {
+		panic(fmt.Errorf("executing template failed: %w", err))
 	}
 	return tplBuffer.String()
 }
@@ -56,23 +91,12 @@ func getFuncMap() template.FuncMap {
 	max := func(a, b int) int { return max(a, b) }
 	append := func(existing []string, add string) []string { return append(existing, add) }
 	replace := func(str, find, rep string) string { return strings.ReplaceAll(str, find, rep) }
+	// hotkey returns the hotkey and altHotkey properties for the n-th item of a menu.
 	hotkey := func(n int) string {
-		var key string
-		if n == 10 {
-			key = "0"
-		} else {
-			key = fmt.Sprintf("%d", n%10)
-		}
-		var hotkey, altHotkey string
-		if n > 10 {
-			hotkey = fmt.Sprintf("mod+shift+%s", key)
-			altHotkey = fmt.Sprintf("alt+shift+%s", key)
-		} else {
-			hotkey = fmt.Sprintf("mod+%s", key)
-			altHotkey = fmt.Sprintf("alt+%s", key)
-		}
+		hotkey, altHotkey := menuHotKeys(n)
 		return fmt.Sprintf("hotkey: '%s',\n    altHotkey: '%s',", hotkey, altHotkey)
 	}
+	// toHeader splits a camel case name into capitalized words dropping a leading N or Is.
 	toHeader := func(s string) string {
 		var words []string
 		start := 0
@@ -95,6 +119,7 @@ func getFuncMap() template.FuncMap {
 		}
 		return strings.Join(words, " ")
 	}
+	// cond returns a if t is true and b otherwise.
 	cond := func(t bool, a, b any) any {
 		if t {
 			return a
@@ -102,6 +127,7 @@ func getFuncMap() template.FuncMap {
 			return b
 		}
 	}
+	// apply executes tmplStr against each item of array and joins the results with sep.
 	apply := func(array []string, tmplStr, sep string) string {
 		var ret string
 		for i, item := range array {
@@ -112,10 +138,11 @@ func getFuncMap() template.FuncMap {
 		}
 		return ret
 	}
+	// regexCompile compiles pattern for use with regexReplace.
 	regexCompile := func(pattern string) *regexp.Regexp {
 		re, err := regexp.Compile(pattern)
 		if err != nil {
-			logger.Fatal(err)
+			panic(err)
 		}
 		return re
 	}
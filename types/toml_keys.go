@@ -0,0 +1,166 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// ignoredKeys are documented (in the README) but not used by goMaker. They are reported
+// so that nobody expects them to do anything.
+var ignoredKeys = map[string]bool{
+	"settings.go_output": true,
+}
+
+// readClassDefinition decodes a class definition into dest. Keys dest does not have are
+// reported as warnings (with the nearest known key, if any) rather than silently dropped.
+func readClassDefinition(path string, dest any) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	decoder := toml.NewDecoder(bytes.NewReader(contents))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(dest)
+
+	var strict *toml.StrictMissingError
+	if errors.As(err, &strict) {
+		// The rest of the file was decoded
+		for _, e := range strict.Errors {
+			row, _ := e.Position()
+			reportUnknownKey(Position{File: path, Line: row}, e.Key(), reflect.TypeOf(dest))
+		}
+		return nil
+	}
+
+	var decodeErr *toml.DecodeError
+	if errors.As(err, &decodeErr) {
+		row, _ := decodeErr.Position()
+		return fmt.Errorf("%s:%d: %w", path, row, err)
+	}
+	return err
+}
+
+// arrayTableLines returns the line of each [[name]] header in the file, in order.
+func arrayTableLines(path, name string) []int {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	ret := []int{}
+	for i, line := range strings.Split(string(contents), "\n") {
+		if strings.ReplaceAll(strings.TrimSpace(line), " ", "") == "[["+name+"]]" {
+			ret = append(ret, i+1)
+		}
+	}
+	return ret
+}
+
+func reportUnknownKey(pos Position, key toml.Key, destType reflect.Type) {
+	fullKey := strings.Join(key, ".")
+	pos = pos.withKey(fullKey)
+	if ignoredKeys[fullKey] {
+		reportWarning(pos, "%s is documented but ignored by goMaker", key[len(key)-1])
+		return
+	}
+
+	table, candidates := "", tomlKeys(destType)
+	if len(key) > 1 {
+		table = key[0]
+		candidates = tomlKeys(fieldType(destType, table))
+	}
+
+	msg := fmt.Sprintf("unknown key %s", key[len(key)-1])
+	if table != "" {
+		msg += " in [" + table + "]"
+	}
+	if suggestion := nearestKey(key[len(key)-1], candidates); suggestion != "" {
+		msg += " (did you mean " + suggestion + "?)"
+	}
+	reportWarning(pos, "%s", msg)
+}
+
+// tomlKeys returns the keys a struct accepts when decoded from TOML.
+func tomlKeys(t reflect.Type) []string {
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	ret := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("toml"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = FirstLower(f.Name)
+		}
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// fieldType returns the type of the field decoded from the given key.
+func fieldType(t reflect.Type, key string) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("toml"), ",")
+		if name == key || (name == "" && strings.EqualFold(f.Name, key)) {
+			return f.Type
+		}
+	}
+	return nil
+}
+
+// nearestKey returns the candidate closest to key if it is close enough to be a likely
+// misspelling.
+func nearestKey(key string, candidates []string) string {
+	best, bestDist := "", len(key)/4+2
+	for _, c := range candidates {
+		if d := editDistance(strings.ToLower(key), strings.ToLower(c)); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
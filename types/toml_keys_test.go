@@ -0,0 +1,102 @@
+package types
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadClassDefinition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "block.toml")
+	contents := `[settings]
+class = "Block"
+cache_tpye = "cacheable"
+go_output = "somewhere"
+
+[[facets]]
+name = "Logs"
+viewTyp = "table"
+`
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ResetDiagnostics()
+	var f struct {
+		Settings Structure `toml:"settings"`
+		Facets   []Facet   `toml:"facets"`
+	}
+	if err := readClassDefinition(path, &f); err != nil {
+		t.Fatal(err)
+	}
+	if f.Settings.Class != "Block" || len(f.Facets) != 1 || f.Facets[0].Name != "Logs" {
+		t.Errorf("the known keys were not decoded: %+v", f)
+	}
+
+	want := []string{
+		"3 settings.cache_tpye unknown key cache_tpye in [settings] (did you mean cache_type?)",
+		"4 settings.go_output go_output is documented but ignored by goMaker",
+		"8 facets.viewTyp unknown key viewTyp in [facets] (did you mean viewType?)",
+	}
+	got := []string{}
+	for _, d := range Diagnostics() {
+		got = append(got, fmt.Sprintf("%d %s %s", d.Line, d.Key, d.Message))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got diagnostics\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	ResetDiagnostics()
+}
+
+// Every key in the README's table of class definition fields must either be read by
+// the loader or be listed as ignored, and the keys listed as ignored must be exactly
+// those the README says goMaker ignores.
+func TestReadmeKeys(t *testing.T) {
+	contents, err := os.ReadFile("../README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, table, found := strings.Cut(string(contents), "The `.toml` file contains the following fields:")
+	if !found {
+		t.Fatal("the README's table of class definition fields was not found")
+	}
+
+	known := tomlKeys(reflect.TypeOf(Structure{}))
+	documented := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(table), "\n")[2:] {
+		if !strings.HasPrefix(line, "|") {
+			break
+		}
+		cells := strings.Split(line, "|")
+		if len(cells) < 4 {
+			t.Errorf("malformed row in the README's table: %s", line)
+			continue
+		}
+		key := strings.TrimSpace(cells[1])
+		documented[key] = true
+
+		isKnown, isIgnored := slices.Contains(known, key), ignoredKeys["settings."+key]
+		switch {
+		case !isKnown && !isIgnored:
+			t.Errorf("the README documents %s but the loader neither reads nor ignores it", key)
+		case isKnown && isIgnored:
+			t.Errorf("%s is read by the loader but listed as ignored", key)
+		}
+		if saysIgnored := strings.Contains(cells[3], "ignored by goMaker"); saysIgnored != isIgnored {
+			t.Errorf("the README says %s is ignored: %t, ignoredKeys says %t", key, saysIgnored, isIgnored)
+		}
+	}
+	if len(documented) == 0 {
+		t.Fatal("the README's table of class definition fields is empty")
+	}
+
+	for key := range ignoredKeys {
+		if !documented[strings.TrimPrefix(key, "settings.")] {
+			t.Errorf("%s is ignored but not documented in the README", key)
+		}
+	}
+}
@@ -3,13 +3,11 @@ package types
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
 	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
-	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/logger"
 )
 
 // CodeBase - the top-level structure for the codebase which carries an array of
@@ -43,7 +41,7 @@ func (cb *CodeBase) Version(verbose bool) string {
 
 // Description - returns the description of the codebase for the openapi.yaml file
 func (cb *CodeBase) Description() string {
-	apiPath := filepath.Join(getTemplatePathNoErr(), "api/description.txt")
+	apiPath := templateFile("api/description.txt")
 	return strings.Trim(file.AsciiFileToString(apiPath), ws)
 }
 
@@ -150,17 +148,20 @@ var knownTypes = map[string]bool{
 	"wei":         true,
 	"AddrRecord":  true,
 	"AppRecord":   true,
+	"Config":      true,
 	"DestType":    true,
 	"StatePart":   true,
 	"StorageSlot": true,
 	"TokenType":   true,
 }
 
-// Validate - called from FinishLoad to validate the codebase
+// Validate reports every problem it finds in the codebase as a diagnostic. It returns an
+// error if any errors have been reported.
 func (cb *CodeBase) Validate() error {
 	structureNames := make(map[string]bool, len(cb.Structures))
 	for _, st := range cb.Structures {
 		structureNames[st.Class] = true
+		st.checkCache()
 		order := make(map[int]bool, 50)
 		for _, m := range st.Members {
 			if m.DocOrder > 0 {
@@ -171,7 +172,7 @@ func (cb *CodeBase) Validate() error {
 			}
 
 			if cb.TypeToGroup(m.Type) == "unknown type: "+m.Type {
-				return fmt.Errorf("unknown type %s in model: %s", m.Type, st.Class)
+				reportError(m.pos, "unknown type %s in model: %s", m.Type, st.Class)
 			}
 		}
 		sorted := make([]int, 0, len(order))
@@ -181,12 +182,16 @@ func (cb *CodeBase) Validate() error {
 		sort.Ints(sorted)
 		for i, v := range sorted {
 			if i+1 != v {
-				return fmt.Errorf("doc_order is not sequential in model: %s", st.Class)
+				pos := st.pos
+				if len(st.Members) > 0 {
+					pos = Position{File: st.Members[0].pos.File}
+				}
+				reportError(pos, "doc_order is not sequential in model: %s", st.Class)
+				break
 			}
 		}
 
-		pwd, _ := os.Getwd()
-		helpFolder := filepath.Join(pwd, "frontend/src/assets/help")
+		helpFolder := getHelpFolder()
 		if file.FolderExists(helpFolder) {
 			first := Lower(st.Parent)
 			if first != "" {
@@ -195,7 +200,7 @@ func (cb *CodeBase) Validate() error {
 			second := Lower(st.UiRouteName())
 			helpFile := filepath.Join(helpFolder, first+second+".md")
 			if strings.Contains(strings.ReplaceAll(helpFile, "trueblocks-", ""), "-") && !file.FileExists(helpFile) {
-				logger.InfoBR("Help file missing:", helpFile)
+				reportWarning(st.pos, "help file missing: %s", helpFile)
 			}
 		}
 	}
@@ -211,49 +216,39 @@ func (cb *CodeBase) Validate() error {
 				continue
 			}
 
-			return fmt.Errorf("unknown types %s.%s in command: %s", stripped, ot, op.LongName)
+			reportError(op.pos, "unknown types %s.%s in command: %s", stripped, ot, op.LongName)
 		}
 	}
 
 	for _, st := range cb.Structures {
 		for _, f := range st.Facets {
-			pwd, _ := os.Getwd()
-			tomlFile := filepath.Join(pwd, "code_gen/templates/classDefinitions", strings.ToLower(f.StoreName)+".toml")
-			csvFile := filepath.Join(pwd, "code_gen/templates/classDefinitions/fields", strings.ToLower(f.StoreName)+".csv")
+			tomlFile := templateFile(filepath.Join("classDefinitions", strings.ToLower(f.StoreName)+".toml"))
+			csvFile := templateFile(filepath.Join("classDefinitions", "fields", strings.ToLower(f.StoreName)+".csv"))
 
 			if !structureNames[f.StoreName] {
 				// The main issue: StoreName is not found in the structureNames map
 				baseMsg := fmt.Sprintf("facet references StoreName '%s' in structure %s, but no structure with Class='%s' found in codebase", f.StoreName, st.Class, f.StoreName)
 
-				var missingFiles []string
+				// The CSV file is optional (the members may be in the TOML file)
 				if !file.FileExists(tomlFile) {
-					missingFiles = append(missingFiles, fmt.Sprintf("TOML file: %s", tomlFile))
-					logger.InfoBR("TOML file missing:", tomlFile)
-				} else {
-					logger.InfoBR("TOML file found:", tomlFile)
-				}
-				if !file.FileExists(csvFile) {
-					missingFiles = append(missingFiles, fmt.Sprintf("CSV file: %s", csvFile))
-					logger.InfoBR("CSV file missing:", csvFile)
+					reportError(st.pos.withKey("facets.store"), "%s. Missing files: TOML file: %s", baseMsg, tomlFile)
+				} else if !file.FileExists(csvFile) {
+					reportError(st.pos.withKey("facets.store"), "%s. Template file exists (%s) but the Class name inside it may not match '%s', or the structure failed to load", baseMsg, tomlFile, f.StoreName)
 				} else {
-					logger.InfoBR("CSV file found:", csvFile)
-				}
-
-				if len(missingFiles) > 0 {
-					return fmt.Errorf("%s. Missing files: %s", baseMsg, strings.Join(missingFiles, ", "))
-				} else {
-					return fmt.Errorf("%s. Template files exist (%s, %s) but the Class name inside %s may not match '%s', or the structure failed to load", baseMsg, tomlFile, csvFile, tomlFile, f.StoreName)
+					reportError(st.pos.withKey("facets.store"), "%s. Template files exist (%s, %s) but the Class name inside %s may not match '%s', or the structure failed to load", baseMsg, tomlFile, csvFile, tomlFile, f.StoreName)
 				}
+				continue
 			}
 
 			if len(st.Members) == 0 {
-				csvFile := filepath.Join(pwd, "code_gen/templates/classDefinitions/fields", strings.ToLower(st.Class)+".csv")
-				return fmt.Errorf("no members found in structure: %s %s", st.Class, csvFile)
+				csvFile := templateFile(filepath.Join("classDefinitions", "fields", strings.ToLower(st.Class)+".csv"))
+				reportError(st.pos, "no members found in structure: %s (expected %s or [[members]] in its TOML file)", st.Class, csvFile)
+				break
 			}
 		}
 	}
 
-	return nil
+	return diagnosticsError()
 }
 
 func (op *Option) Stripped() string {
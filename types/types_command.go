@@ -9,7 +9,6 @@ import (
 	"unicode"
 
 	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
-	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/utils"
 )
 
@@ -84,7 +83,7 @@ func (c *Command) HasPositionals() bool {
 }
 
 func (c *Command) HasExample() bool {
-	examplePath := filepath.Join(getTemplatePathNoErr(), "api/examples/"+c.Route+".json")
+	examplePath := templateFile("api/examples/" + c.Route + ".json")
 	return file.FileExists(examplePath)
 }
 
@@ -108,6 +107,7 @@ func (c *Command) HasAddrs() bool {
 
 func (c *Command) Clean() {
 	cleaned := []Option{}
+	cmdPos := Position{}
 	c.Notes = []string{}
 	c.Sorts = []string{}
 	c.Aliases = []string{}
@@ -126,6 +126,17 @@ func (c *Command) Clean() {
 					e = strings.ReplaceAll(e, "*", "")
 					op.DefaultEnum = e
 				}
+				// some may list the values it stands for: some(balance+proxy)
+				if name, list, ok := strings.Cut(e, "("); ok {
+					if op.DefaultEnum == e {
+						op.DefaultEnum = strings.TrimSpace(name)
+					}
+					e = strings.TrimSpace(name)
+					if e != "some" {
+						reportError(op.pos.withKey("data_type"), "only the some value of an enum may list values (--%s in %s)", op.LongName, op.Route)
+					}
+					op.someEnums = splitNames(strings.ReplaceAll(strings.TrimSuffix(strings.TrimSpace(list), ")"), "+", ","))
+				}
 				op.Enums[i] = e
 			}
 			if strings.Contains(op.DataType, "list<enum") {
@@ -138,13 +149,14 @@ func (c *Command) Clean() {
 		switch op.OptionType {
 		case "note":
 			if !strings.HasSuffix(op.Description, ".") {
-				logger.Warn("Note does not end with a period: " + op.Description)
+				reportWarning(op.pos, "note does not end with a period: %s", op.Description)
 			}
 			c.Notes = append(c.Notes, op.Description)
 		case "alias":
 			c.Aliases = append(c.Aliases, op.Description)
 		case "command":
 			c.Description = op.Description
+			cmdPos = op.pos
 		case "group":
 			// c.Description = op.Description
 		default:
@@ -190,7 +202,7 @@ func (c *Command) Clean() {
 					suggestion += string(char)
 				}
 			}
-			logger.Warn(fmt.Sprintf("Option '%s' in command '%s': LongName '%s' should not contain capital letters. Suggestion: '%s'", op.LongName, c.Route, op.LongName, suggestion))
+			reportWarning(op.pos, "option '%s' in command '%s': LongName '%s' should not contain capital letters. Suggestion: '%s'", op.LongName, c.Route, op.LongName, suggestion)
 		}
 
 		// Rule 2: Check Handler values
@@ -199,12 +211,12 @@ func (c *Command) Clean() {
 			handlerVal := int(op.Handler) // Convert float64 to int
 
 			if handlerVal <= 0 { // Handlers should be positive integers
-				logger.Warn(fmt.Sprintf("Option '%s' in command '%s': Handler value '%f' must be a positive integer.", op.LongName, c.Route, op.Handler))
+				reportWarning(op.pos, "option '%s' in command '%s': Handler value '%f' must be a positive integer", op.LongName, c.Route, op.Handler)
 				continue
 			}
 
 			if _, exists := handlerValues[handlerVal]; exists {
-				logger.Warn(fmt.Sprintf("Option '%s' in command '%s': Duplicate Handler value '%d'.", op.LongName, c.Route, handlerVal))
+				reportWarning(op.pos, "option '%s' in command '%s': duplicate Handler value '%d'", op.LongName, c.Route, handlerVal)
 			} else {
 				handlerValues[handlerVal] = true
 			}
@@ -219,7 +231,7 @@ func (c *Command) Clean() {
 	if maxHandler > 0 {
 		for i := 1; i <= maxHandler; i++ {
 			if _, exists := handlerValues[i]; !exists {
-				logger.Warn(fmt.Sprintf("Command '%s': Missing Handler value '%d' in the sequence.", c.Route, i))
+				reportWarning(cmdPos, "command '%s': missing Handler value '%d' in the sequence", c.Route, i)
 			}
 		}
 	}
@@ -254,19 +266,28 @@ var globals = []Option{
 	// "append","file","names","noColor","noop","output","verbose","version","wei",
 }
 
-func (c *Command) PyGlobals() string {
-	ret := []string{}
+// commandGlobals returns the globals the command accepts given its capabilities.
+func (c *Command) commandGlobals() []Option {
+	ret := []Option{}
 	caps := strings.ReplaceAll(strings.ReplaceAll(strings.ToLower(c.Capabilities)+"|", "default|", "verbose|fmt|version|noop|nocolor|chain|noheader|file|output|append|"), "caching|", "cache|decache|")
 	if c.Route == "names" {
 		caps = "create|update|delete|undelete|remove|" + caps
 	}
 	for _, glob := range globals {
 		if strings.Contains(caps, strings.ToLower(glob.LongName)+"|") {
-			tmplName := "pyglobals1"
-			tmpl := "    \"{{toCamel .LongName}}\": {\"hotkey\": \"{{.PyHotKey}}\", \"type\": \"{{.OptionType}}\"},"
-			ret = append(ret, glob.executeTemplate(tmplName, tmpl))
+			ret = append(ret, glob)
 		}
 	}
+	return ret
+}
+
+func (c *Command) PyGlobals() string {
+	ret := []string{}
+	for _, glob := range c.commandGlobals() {
+		tmplName := "pyglobals1"
+		tmpl := "    \"{{toCamel .LongName}}\": {\"hotkey\": \"{{.PyHotKey}}\", \"type\": \"{{.OptionType}}\"},"
+		ret = append(ret, glob.executeTemplate(tmplName, tmpl))
+	}
 	return strings.Join(ret, "\n")
 }
 
@@ -477,7 +498,7 @@ func (c *Command) TestLogs() string {
 }
 
 func (c *Command) PackageComments() string {
-	docsPath := filepath.Join(getTemplatePathNoErr(), "readme-intros/"+c.Route+".md")
+	docsPath := templateFile("readme-intros/" + c.Route + ".md")
 	lines := file.AsciiFileToLines(docsPath)
 
 	ret := []string{"// " + c.Route + "Pkg implements the chifra " + c.Route + " command.\n//"}
@@ -500,7 +521,7 @@ func (c *Command) IsRoute() bool {
 }
 
 func (c *Command) Example() string {
-	examplePath := filepath.Join(getTemplatePathNoErr(), "api/examples/"+c.Route+".json")
+	examplePath := templateFile("api/examples/" + c.Route + ".json")
 	contents := strings.Trim(file.AsciiFileToString(examplePath), ws)
 	contents = strings.ReplaceAll(contents, "\n", "\n                  ")
 	return strings.Trim(contents, ws) + "\n"
@@ -511,14 +532,14 @@ func (c *Command) ReadmeName() string {
 }
 
 func (c *Command) HelpIntro() string {
-	readmePath := filepath.Join(getTemplatePathNoErr(), "readme-intros/", c.ReadmeName())
+	readmePath := templateFile(filepath.Join("readme-intros", c.ReadmeName()))
 	tmplName := "helpIntro" + c.ReadmeName()
 	tmpl := file.AsciiFileToString(readmePath)
 	if tmpl == "" {
-		logger.Fatal("Could not read template file: ", readmePath)
+		fail("could not read template file: %s", readmePath)
 	}
 	if err := ValidateTemplate(tmpl, readmePath); err != nil {
-		logger.Fatal(err)
+		panic(err)
 	}
 	return strings.Trim(c.executeTemplate(tmplName, tmpl), ws)
 }
@@ -529,7 +550,7 @@ func (c *Command) HelpText() string {
 	utils.System("chifra " + c.Route + " --help 2>" + readmePath)
 	helpText := strings.Trim(file.AsciiFileToString(readmePath), wss)
 	if strings.Contains(helpText, "unknown") {
-		logger.Fatal("Error: " + helpText)
+		fail("%s", helpText)
 	}
 	return helpText
 }
@@ -563,7 +584,7 @@ func (c *Command) HelpLinks() string {
 }
 
 func (c *Command) ReadmeFooter() string {
-	footerFile := filepath.Join(getTemplatePathNoErr(), "readme-intros/README.footer.md")
+	footerFile := templateFile("readme-intros/README.footer.md")
 	return strings.Trim(file.AsciiFileToString(footerFile), ws)
 }
 
@@ -582,7 +603,7 @@ func (c *Command) GroupMenu(reason string) string {
   chifra:
     parent: commands`
 	default:
-		logger.Fatal("Unknown reason for group menu:", reason)
+		fail("unknown reason for group menu: %s", reason)
 		return ""
 	}
 }
@@ -626,7 +647,7 @@ func (c *Command) GroupMarkdowns(reason, filter string) string {
 			}
 		}
 	default:
-		logger.Fatal("Error: unknown reason: " + reason)
+		fail("unknown reason: %s", reason)
 	}
 	return strings.Join(ret, "\n")
 }
@@ -914,7 +935,7 @@ func (c *Command) TsOptions() string {
 			case "names", "noColor", "noop", "verbose", "version":
 				// do nothing
 			default:
-				logger.Fatal("Should not happen: " + cap)
+				fail("should not happen: %s", cap)
 			}
 		}
 	}
@@ -1072,7 +1093,7 @@ func (op *Option) FindDeprecator() *Option {
 		return &op
 	}
 
-	logger.Fatal(fmt.Sprintf("Deprecator (%s) not found for: %s", parts[1], op.LongName))
+	fail("deprecator (%s) not found for: %s", parts[1], op.LongName)
 	return nil
 }
 
@@ -2,6 +2,7 @@ package types
 
 import (
 	"strings"
+	"unicode"
 
 	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
 )
@@ -68,17 +69,111 @@ func (op *Option) EnumName() string {
 	return strings.ToUpper(op.Route[0:1]) + op.Route[1:] + op.GoName
 }
 
+// EnumTag returns the prefix of the constant for the enum's value e (or "none"). It is
+// the first letters of the route and option unless the option's attributes give one
+// (prefix=XY) or that would produce the same constant as another enum (see nameEnums).
 func (op *Option) EnumTag(e string) string {
+	if tag, ok := op.enumTags[e]; ok {
+		return tag
+	}
+	return op.enumTag(1)
+}
+
+// enumTag returns the prefix made of the first n letters of the route and the first
+// letter of the option (or the option's explicit prefix).
+func (op *Option) enumTag(n int) string {
+	if prefix := op.attributeValue("prefix"); prefix != "" {
+		return prefix
+	}
 	if len(op.Route) < 2 || len(op.GoSdkName) < 2 {
 		return ""
 	}
-	tag := strings.ToUpper(op.Route[0:1]) + op.GoSdkName[0:1]
-	if tag == "CM" && e == "none" {
-		tag = strings.ToUpper(op.Route[0:2]) + op.GoSdkName[0:1]
-	} else if tag == "SP" && (e == "none" || e == "all" || e == "some") {
-		tag = strings.ToUpper(op.Route[0:2]) + op.GoSdkName[0:1]
+	return strings.ToUpper(op.Route[0:min(n, len(op.Route))]) + op.GoSdkName[0:1]
+}
+
+// enumConstant returns the name of the constant for the enum's value e (or "none").
+func enumConstant(tag, e string) string {
+	if e == "none" {
+		return "No" + tag
+	}
+	return tag + FirstUpper(e)
+}
+
+// nameEnums chooses the prefixes of the constants of every enum so that no two constants
+// (which share a package) have the same name. A constant that would collide with another
+// uses more letters of its route until it does not. Collisions that remain (because of
+// an explicit prefix or routes that start the same way) are reported.
+func (cb *CodeBase) nameEnums() {
+	type constant struct {
+		op    *Option
+		value string
+		n     int
+	}
+	constants := []*constant{}
+	for i := range cb.Commands {
+		for j := range cb.Commands[i].Options {
+			op := &cb.Commands[i].Options[j]
+			if !op.IsEnum() {
+				continue
+			}
+			if prefix := op.attributeValue("prefix"); prefix != "" && !isLetters(prefix) {
+				reportError(op.pos.withKey("attributes"), "prefix %s of --%s in %s must be letters only", prefix, op.LongName, op.Route)
+			}
+			for _, v := range op.someEnums {
+				if !contains(op.Enums, v) || v == "some" || v == "all" {
+					reportError(op.pos.withKey("data_type"), "some of --%s in %s lists %s, which is not one of its values", op.LongName, op.Route, v)
+				}
+			}
+			for _, e := range append([]string{"none"}, op.Enums...) {
+				constants = append(constants, &constant{op: op, value: e, n: 1})
+			}
+		}
+	}
+
+	byName := func() map[string][]*constant {
+		ret := map[string][]*constant{}
+		for _, c := range constants {
+			name := enumConstant(c.op.enumTag(c.n), c.value)
+			ret[name] = append(ret[name], c)
+		}
+		return ret
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, same := range byName() {
+			if len(same) < 2 {
+				continue
+			}
+			for _, c := range same {
+				if c.op.attributeValue("prefix") == "" && c.n < len(c.op.Route) {
+					c.n++
+					changed = true
+				}
+			}
+		}
+	}
+
+	names := byName()
+	for _, c := range constants {
+		if c.op.enumTags == nil {
+			c.op.enumTags = map[string]string{}
+		}
+		c.op.enumTags[c.value] = c.op.enumTag(c.n)
+		name := enumConstant(c.op.enumTag(c.n), c.value)
+		if same := names[name]; len(same) > 1 && same[0] != c {
+			other := same[0]
+			reportError(c.op.pos.withKey("data_type"), "the constant %s for %s of --%s in %s is also generated for %s of --%s in %s (%s). Give one of them a prefix (prefix=XY in its attributes).", name, c.value, c.op.LongName, c.op.Route, other.value, other.op.LongName, other.op.Route, other.op.pos)
+		}
+	}
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			return false
+		}
 	}
-	return tag
+	return true
 }
 
 func (op *Option) EnumNone() string {
@@ -98,8 +193,12 @@ func (op *Option) EnumDef() string {
 		token := tag + FirstUpper(e)
 		switch e {
 		case "some":
-			if op.Route == "state" {
-				some = []string{"SPBalance", "SPProxy", "SPDeployed", "SPAccttype"}
+			// Unless it lists its values, some stands for the first four
+			if len(op.someEnums) > 0 {
+				some = []string{}
+				for _, v := range op.someEnums {
+					some = append(some, op.EnumTag(v)+FirstUpper(v))
+				}
 			}
 			token += "=" + strings.Join(some, "|")
 		case "all":
@@ -175,6 +274,11 @@ func (op *Option) EnumCases() string {
 	return strings.Join(ret, "\n")
 }
 
+// ValidateEnums returns true if the file at path contains the enums joined with '|'
+// (and the joined string).
+//
+// Deprecated: the enum validators are now checked by parsing each route's validate.go.
+// Use CodeBase.VerifyValidators instead.
 func ValidateEnums(path string, enums []string) (bool, string) {
 	if len(enums) == 0 {
 		return true, ""
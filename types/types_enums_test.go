@@ -0,0 +1,70 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNameEnums(t *testing.T) {
+	ResetDiagnostics()
+	defer ResetDiagnostics()
+
+	enum := func(route, longName, attributes string, values ...string) Option {
+		return Option{
+			Route:      route,
+			LongName:   longName,
+			GoSdkName:  FirstUpper(longName),
+			Attributes: attributes,
+			DataType:   "enum",
+			Enums:      values,
+			pos:        Position{File: "options.csv"},
+		}
+	}
+	cb := CodeBase{Commands: []Command{
+		{Route: "config", Options: []Option{enum("config", "mode", "", "show", "edit")}},
+		{Route: "chunks", Options: []Option{enum("chunks", "mode", "", "index", "pins")}},
+		{Route: "state", Options: []Option{enum("state", "parts", "", "balance", "some", "all")}},
+		{Route: "slurp", Options: []Option{enum("slurp", "parts", "", "ext", "some", "all")}},
+		{Route: "status", Options: []Option{enum("status", "modes", "visible|prefix=ST", "index")}},
+		{Route: "stats", Options: []Option{enum("stats", "tables", "", "index")}},
+	}}
+	cb.nameEnums()
+
+	tests := []struct {
+		cmd   int
+		value string
+		want  string
+	}{
+		{0, "none", "COM"},
+		{0, "show", "CM"},
+		{1, "none", "CHM"},
+		{1, "index", "CM"},
+		{2, "balance", "SP"},
+		{2, "some", "STP"},
+		{2, "all", "STP"},
+		{3, "ext", "SP"},
+		{3, "none", "SLP"},
+		{4, "index", "ST"},
+		{5, "index", "STT"},
+	}
+	for _, tt := range tests {
+		op := &cb.Commands[tt.cmd].Options[0]
+		if got := op.EnumTag(tt.value); got != tt.want {
+			t.Errorf("%s --%s: EnumTag(%s) = %s, want %s", op.Route, op.LongName, tt.value, got, tt.want)
+		}
+	}
+	if d := Diagnostics(); len(d) != 0 {
+		t.Errorf("unexpected diagnostics: %v", d)
+	}
+
+	// Routes that start the same way cannot be told apart without a prefix
+	cb = CodeBase{Commands: []Command{
+		{Route: "list", Options: []Option{enum("list", "flow", "", "in")}},
+		{Route: "list", Options: []Option{enum("list", "format", "", "in")}},
+	}}
+	cb.nameEnums()
+	d := Diagnostics()
+	if len(d) != 2 || !strings.Contains(d[0].Message+d[1].Message, "constant NoLISTF") || !strings.Contains(d[0].Message+d[1].Message, "constant LISTFIn") {
+		t.Errorf("expected the collisions to be reported, got %v", d)
+	}
+}
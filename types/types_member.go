@@ -5,24 +5,36 @@ import (
 	"fmt"
 	"strings"
 	"unicode"
-
-	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/logger"
 )
 
 type Member struct {
-	Name        string     `json:"name,omitempty" csv:"name"`
-	Type        string     `json:"type,omitempty" csv:"type"`
-	StrDefault  string     `json:"strDefault,omitempty" csv:"strDefault"`
-	Attributes  string     `json:"attributes,omitempty" csv:"attributes"`
-	Section     string     `json:"section,omitempty" csv:"section"`
-	DocOrder    int        `json:"docOrder,omitempty" csv:"docOrder"`
-	Upgrades    string     `json:"upgrades,omitempty" csv:"upgrades"`
-	Description string     `json:"description,omitempty" csv:"description"`
-	Label       string     `json:"label,omitempty" csv:"label"`
-	Num         int        `json:"num"`
-	IsArray     bool       `json:"isArray,omitempty"`
-	IsPointer   bool       `json:"isPointer,omitempty"`
+	Name        string     `json:"name,omitempty" toml:"name" csv:"name"`
+	Type        string     `json:"type,omitempty" toml:"type" csv:"type"`
+	StrDefault  string     `json:"strDefault,omitempty" toml:"strDefault" csv:"strDefault"`
+	Attributes  string     `json:"attributes,omitempty" toml:"attributes" csv:"attributes"`
+	Section     string     `json:"section,omitempty" toml:"section" csv:"section"`
+	DocOrder    int        `json:"docOrder,omitempty" toml:"docOrder" csv:"docOrder"`
+	Upgrades    string     `json:"upgrades,omitempty" toml:"upgrades" csv:"upgrades"`
+	Description string     `json:"description,omitempty" toml:"description" csv:"description"`
+	Label       string     `json:"label,omitempty" toml:"label" csv:"label"`
+	Num         int        `json:"num" toml:"-"`
+	IsArray     bool       `json:"isArray,omitempty" toml:"-"`
+	IsPointer   bool       `json:"isPointer,omitempty" toml:"-"`
 	stPtr       *Structure `json:"-"`
+	pos         Position   `json:"-" csv:"-"`
+}
+
+// Pos returns where the member is defined.
+func (m *Member) Pos() Position {
+	return m.pos
+}
+
+func (m *Member) setPos(pos Position) {
+	m.pos = pos
+}
+
+func (m *Member) requiredColumns() []string {
+	return []string{"name", "type", "docOrder", "description"}
 }
 
 func (m *Member) String() string {
@@ -507,7 +519,7 @@ func (m *Member) YamlType() string {
 	} else if m.Type == "bool" || m.Type == "uint8" {
 		return "boolean\n          format: boolean"
 	} else {
-		logger.Fatal(fmt.Sprintf("unknown type '%s' in Member '%s'\n", m.Type, m.Name))
+		fail("unknown type '%s' in Member '%s'", m.Type, m.Name)
 		return "unknown" + f
 	}
 }
@@ -649,7 +661,7 @@ func (m *Member) GetFormatter() string {
 		return fieldType
 	default:
 		if len(fieldType) > 0 && (strings.ToUpper(string(fieldType[0]))[0] != fieldType[0]) {
-			logger.Fatal("unknown field type:", fieldType)
+			fail("unknown field type: %s", fieldType)
 		}
 	}
 
@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
-	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/logger"
 )
 
 func (c *Command) HasNotes() bool {
@@ -13,16 +12,15 @@ func (c *Command) HasNotes() bool {
 }
 
 func (c *Command) HelpNotes() string {
-	readmePath := filepath.Join(getTemplatePathNoErr(), "readme-intros", c.ReadmeName())
-	readmePath = strings.ReplaceAll(readmePath, ".md", ".notes.md")
+	readmePath := templateFile(filepath.Join("readme-intros", strings.ReplaceAll(c.ReadmeName(), ".md", ".notes.md")))
 	if file.FileExists(readmePath) {
 		tmplName := "Notes" + c.ReadmeName()
 		tmpl := file.AsciiFileToString(readmePath)
 		if tmpl == "" {
-			logger.Fatal("Could not read template file: ", readmePath)
+			fail("could not read template file: %s", readmePath)
 		}
 		if err := ValidateTemplate(tmpl, readmePath); err != nil {
-			logger.Fatal(err)
+			panic(err)
 		}
 		return "\n\n" + strings.Trim(c.executeTemplate(tmplName, tmpl), ws)
 	}
@@ -34,6 +34,20 @@ type Option struct {
 	GoSdkType     string   `json:"go_sdk_type"`
 	GoOptionsType string   `json:"go_options_type"`
 	cmdPtr        *Command `json:"-" csv:"-"`
+	pos           Position `json:"-" csv:"-"`
+	// someEnums are the values the enum's some value stands for (if listed)
+	someEnums []string
+	// enumTags are the prefixes of the enum's constants by value (see nameEnums)
+	enumTags map[string]string
+}
+
+// Pos returns where the option is defined.
+func (op *Option) Pos() Position {
+	return op.pos
+}
+
+func (op *Option) setPos(pos Position) {
+	op.pos = pos
 }
 
 func (op *Option) IsRequired() bool {
@@ -60,6 +74,16 @@ func (op *Option) IsConfig() bool {
 	return strings.Contains(op.Attributes, "config")
 }
 
+// attributeValue returns the value of an attribute given as key=value (or an empty string).
+func (op *Option) attributeValue(key string) string {
+	for _, attr := range strings.Split(op.Attributes, "|") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(attr), "="); ok && k == key {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
 func (op *Option) IsEnum() bool {
 	return strings.Contains(op.DataType, "enum")
 }
@@ -98,6 +122,7 @@ func readCmdOption(op *Option, data *any) (bool, error) {
 	op.Description = strings.ReplaceAll(op.Description, "&#44;", ",")
 	op.Description = strings.ReplaceAll(op.Description, "&#39;", "'")
 	op.Description = strings.ReplaceAll(op.Description, "`", "")
+	op.Summary = strings.ReplaceAll(op.Summary, "&#44;", ",")
 
 	op.DefVal = strings.ReplaceAll(op.DefVal, "NOPOS", "base.NOPOS")
 
@@ -36,7 +36,7 @@ func (s *Store) Members() []Member {
 	for _, st := range s.sPtr.cbPtr.Structures {
 		sName := Lower(Singular(st.Class))
 		if strings.EqualFold(sing, sName) {
-			ret := st.Members
+			ret := append([]Member{}, st.Members...)
 			sort.Slice(ret, func(i, j int) bool {
 				return ret[i].DocOrder < ret[j].DocOrder
 			})
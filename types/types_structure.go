@@ -10,7 +10,6 @@ import (
 
 	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/base"
 	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
-	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/logger"
 )
 
 type Structure struct {
@@ -24,8 +23,12 @@ type Structure struct {
 	UiIcon       string    `json:"ui_icon,omitempty" toml:"ui_icon"`
 	ProducedBy   string    `json:"produced_by,omitempty" toml:"produced_by"`
 	ContainedBy  string    `json:"contained_by,omitempty" toml:"contained_by"`
+	Contains     string    `json:"contains,omitempty" toml:"contains"`
 	Parent       string    `json:"parent,omitempty" toml:"parent"`
 	Children     string    `json:"children,omitempty" toml:"children"`
+	Extends      string    `json:"extends,omitempty" toml:"extends"`
+	Mixins       []string  `json:"mixins,omitempty" toml:"mixins"`
+	Exclude      []string  `json:"exclude,omitempty" toml:"exclude"`
 	CacheAs      string    `json:"cache_as,omitempty" toml:"cache_as"`
 	CacheBy      string    `json:"cache_by,omitempty" toml:"cache_by"`
 	CacheType    string    `json:"cache_type,omitempty" toml:"cache_type"`
@@ -44,6 +47,17 @@ type Structure struct {
 	Producers    []string  `json:"-" toml:"-"`
 	ChildTabs    []string  `json:"-" toml:"-"`
 	cbPtr        *CodeBase `json:"-" toml:"-"`
+	pos          Position  `json:"-" toml:"-"`
+}
+
+// Pos returns where the structure is defined (its TOML file, or its line in
+// base-types.csv).
+func (s *Structure) Pos() Position {
+	return s.pos
+}
+
+func (s *Structure) setPos(pos Position) {
+	s.pos = pos
 }
 
 func (s *Structure) executeTemplate(name, tmplCode string) string {
@@ -60,13 +74,13 @@ func (s Structure) Validate() bool {
 	if s.StoreType != "" {
 		validStoreTypes := []string{"singleton", "chain-scoped", "address-scoped", "not-scoped"}
 		if !slices.Contains(validStoreTypes, s.StoreType) {
-			logger.Fatal("Invalid StoreType for structure", s.Class, ":", s.StoreType, ". Must be one of:", strings.Join(validStoreTypes, ", "))
+			fail("invalid StoreType for structure %s: %s. Must be one of: %s", s.Class, s.StoreType, strings.Join(validStoreTypes, ", "))
 		}
 	}
 
 	for _, facet := range s.Facets {
 		if err := facet.ValidateAll(); err != nil {
-			logger.Fatal("Facet validation failed:", err)
+			fail("facet validation failed: %w", err)
 		}
 	}
 	return true
@@ -81,7 +95,7 @@ func (s *Structure) Num() int {
 	if len(parts) > 1 {
 		return int(base.MustParseInt64(parts[0]))
 	}
-	logger.Fatal("unknown group: " + s.DocGroup)
+	fail("unknown group: %s", s.DocGroup)
 	return 0
 }
 
@@ -109,7 +123,7 @@ func (s *Structure) IsCacheAsGroup() bool {
 }
 
 func (s *Structure) HasNotes() bool {
-	notePath := filepath.Join(getTemplatePathNoErr(), "model-intros/", CamelCase(s.Class)+".notes.md")
+	notePath := templateFile(filepath.Join("model-intros", CamelCase(s.Class)+".notes.md"))
 	return file.FileExists(notePath)
 }
 
@@ -144,16 +158,16 @@ func (s *Structure) GroupName() string {
 	if len(parts) > 1 {
 		return LowerNoSpaces(parts[1])
 	}
-	logger.Fatal("unknown group: " + s.DocGroup)
+	fail("unknown group: %s", s.DocGroup)
 	return ""
 }
 
 func (s *Structure) ModelIntro() string {
 	tmplName := "modelIntro" + s.Class
 	introName := filepath.Join("model-intros", CamelCase(s.Class))
-	fullIntroPath := filepath.Join(getTemplatePathNoErr(), introName+".md")
+	fullIntroPath := templateFile(introName + ".md")
 	if !file.FileExists(fullIntroPath) {
-		logger.Fatal("missing model intro file:", fullIntroPath, "            ")
+		fail("missing model intro file: %s", fullIntroPath)
 	}
 	tmpl := strings.Trim(getTemplateContents(introName), ws)
 	return s.executeTemplate(tmplName, tmpl)
@@ -193,8 +207,16 @@ func (s *Structure) ModelNotes() string {
 	return strings.Trim(s.executeTemplate(tmplName, tmpl), ws)
 }
 
+// CacheLoc returns the name of the cache the structure is stored in. A structure that
+// extends another one cached by the same fields shares its cache.
 func (s *Structure) CacheLoc() string {
-	if s.Class == "LightBlock" {
+	if s.Extends != "" && s.cbPtr != nil {
+		if base := s.cbPtr.findStructure(Lower(s.Extends)); base != nil && base.CacheBy == s.CacheBy {
+			return base.CacheLoc()
+		}
+	}
+	if s.Class == "LightBlock" && s.Extends == "" {
+		// for class definitions that predate extends
 		return "Block"
 	}
 	return s.Class
@@ -223,7 +245,7 @@ func (s *Structure) CacheIdStr() string {
 	case "filename":
 		return "s.Filename"
 	default:
-		logger.Fatal("Unknown cache by format:", s.CacheBy)
+		fail("unknown cache by format: %s", s.CacheBy)
 		return ""
 	}
 }
@@ -267,7 +289,7 @@ func (s *Structure) findItems() Member {
 			return m
 		}
 	}
-	logger.Fatal("no item in structure: ", s.Class)
+	fail("no item in structure: %s", s.Class)
 	return Member{}
 }
 
@@ -279,7 +301,7 @@ func (s *Structure) ItemName() string {
 	m := s.findItems()
 	parts := strings.Split(m.Type, ".")
 	if len(parts) < 2 {
-		logger.Fatal("bad embed type (needs two parts): ", m.Type)
+		fail("bad embed type (needs two parts): %s", m.Type)
 	}
 	return parts[1]
 }
@@ -288,7 +310,7 @@ func (s *Structure) ItemType() string {
 	m := s.findItems()
 	parts := strings.Split(m.Type, ".")
 	if len(parts) < 2 {
-		logger.Fatal("bad embed type (needs two parts): ", m.Type)
+		fail("bad embed type (needs two parts): %s", m.Type)
 	}
 	if strings.HasPrefix(parts[0], "types") {
 		return FirstUpper(parts[1])
@@ -301,7 +323,7 @@ func (s *Structure) EmbedName() string {
 		if m.IsEmbed() {
 			parts := strings.Split(m.Type, ".")
 			if len(parts) < 2 {
-				logger.Fatal("bad embed type (needs two parts): ", m.Type)
+				fail("bad embed type (needs two parts): %s", m.Type)
 			}
 			return Lower(parts[1])
 		}
@@ -314,7 +336,7 @@ func (s *Structure) EmbedType() string {
 		if m.IsEmbed() {
 			parts := strings.Split(m.Type, ".")
 			if len(parts) < 2 {
-				logger.Fatal("bad embed type (needs two parts): ", m.Type)
+				fail("bad embed type (needs two parts): %s", m.Type)
 			}
 			if parts[0] == "types" {
 				return FirstUpper(parts[1])
@@ -356,8 +378,7 @@ func (s *Structure) SortsInstance() string {
 
 func (s *Structure) getUiRoutePart(p int) string {
 	parts := strings.Split(s.UiRoute, "-")
-	if len(parts) != 3 {
-		s.UiRoute += "-none"
+	for len(parts) < 3 {
 		parts = append(parts, "none")
 	}
 	return parts[p]
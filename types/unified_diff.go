@@ -0,0 +1,224 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+const diffContext = 3
+
+type diffOp struct {
+	kind byte // ' ', '-', or '+'
+	line string
+}
+
+// UnifiedDiff returns a unified diff (with three lines of context) that turns oldText
+// into newText. The result is empty if the two are identical.
+func UnifiedDiff(path, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var sb strings.Builder
+	fromName, toName := "a/"+path, "b/"+path
+	if oldText == "" {
+		fromName = "/dev/null"
+	}
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+
+	// Walk the operations, collecting hunks of changes surrounded by context
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		start := max(0, i-diffContext)
+		hunkOld := oldLine - (i - start)
+		hunkNew := newLine - (i - start)
+
+		// Extend the hunk until we find more than twice the context of unchanged lines
+		end, same := i, 0
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind == ' ' {
+				same++
+				if same > 2*diffContext {
+					break
+				}
+			} else {
+				same = 0
+				end = j + 1
+			}
+		}
+		end = min(len(ops), end+diffContext)
+
+		nOld, nNew := 0, 0
+		var body strings.Builder
+		for _, op := range ops[start:end] {
+			switch op.kind {
+			case ' ':
+				nOld++
+				nNew++
+			case '-':
+				nOld++
+			case '+':
+				nNew++
+			}
+			body.WriteByte(op.kind)
+			body.WriteString(op.line)
+			body.WriteByte('\n')
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(hunkOld, nOld), hunkRange(hunkNew, nNew))
+		sb.WriteString(body.String())
+
+		for _, op := range ops[i:end] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		i = end
+	}
+
+	return sb.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// maxEditDistance bounds the work (and memory) spent on very different inputs. Past this
+// many edits, the middle of the file is reported as entirely replaced.
+const maxEditDistance = 2000
+
+// diffLines computes a shortest edit script between a and b using Myers' algorithm after
+// stripping the lines the two have in common at either end.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := []diffOp{}
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+func replaceAll(a, b []string) []diffOp {
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a {
+		ops = append(ops, diffOp{'-', line})
+	}
+	for _, line := range b {
+		ops = append(ops, diffOp{'+', line})
+	}
+	return ops
+}
+
+func myersDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return replaceAll(a, b)
+	}
+
+	maxD := min(n+m, maxEditDistance)
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	trace := [][]int{}
+
+	found := false
+	for d := 0; d <= maxD && !found; d++ {
+		trace = append(trace, append([]int{}, v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return replaceAll(a, b)
+	}
+
+	// Backtrack through the saved frontiers to recover the edit script. Each saved
+	// frontier covers diagonals -d-1 through d+1.
+	ops := []diffOp{}
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d+1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		if d == 0 {
+			prevX, prevY = 0, 0
+		}
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{' ', a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				ops = append(ops, diffOp{'+', b[y]})
+			} else {
+				x--
+				ops = append(ops, diffOp{'-', a[x]})
+			}
+		}
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
@@ -0,0 +1,42 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	// Identical inputs produce no diff
+	if diff := UnifiedDiff("a.go", "one\ntwo\n", "one\ntwo\n"); diff != "" {
+		t.Errorf("Expected no diff, got %q", diff)
+	}
+
+	// A single changed line with context
+	oldText := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	newText := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n"
+	expected := "--- a/a.go\n+++ b/a.go\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n"
+	if diff := UnifiedDiff("a.go", oldText, newText); diff != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, diff)
+	}
+
+	// Changes far apart produce separate hunks
+	oldText = "a\n1\n2\n3\n4\n5\n6\n7\n8\nb\n"
+	newText = "A\n1\n2\n3\n4\n5\n6\n7\n8\nB\n"
+	expected = "--- a/a.go\n+++ b/a.go\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n@@ -7,4 +7,4 @@\n 6\n 7\n 8\n-b\n+B\n"
+	if diff := UnifiedDiff("a.go", oldText, newText); diff != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, diff)
+	}
+
+	// A new file
+	expected = "--- /dev/null\n+++ b/a.go\n@@ -0,0 +1,2 @@\n+one\n+two\n"
+	if diff := UnifiedDiff("a.go", "", "one\ntwo\n"); diff != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, diff)
+	}
+
+	// Insertions and deletions in the middle of a file
+	oldText = "a\nb\nc\nd\ne\n"
+	newText = "a\nc\nx\nd\ne\n"
+	expected = "--- a/a.go\n+++ b/a.go\n@@ -1,5 +1,5 @@\n a\n-b\n c\n+x\n d\n e\n"
+	if diff := UnifiedDiff("a.go", oldText, newText); diff != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, diff)
+	}
+}
@@ -3,7 +3,6 @@ package types
 import (
 	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -29,7 +28,7 @@ type TemplateMetadata struct {
 
 func shouldProcess(source, subPath, tag string) (bool, error) {
 	_ = subPath
-	single := os.Getenv("TB_MAKER_SINGLE")
+	single := getSingle()
 	if single != "" && !strings.Contains(source, single) {
 		// logger.Warn("skipping ", source, " because of ", single)
 		return false, nil
@@ -74,12 +73,12 @@ func getGeneratorContentsAndDest(fullPath, subPath, group, reason, routeTag, typ
 	// fullPath should already include the complete path to the generator file
 	gPath := fullPath
 	if !file.FileExists(gPath) {
-		logger.Fatal("Could not find generator file: ", gPath)
+		fail("could not find generator file: %s", gPath)
 	}
 
 	tmpl := file.AsciiFileToString(gPath)
 	if err := ValidateTemplate(tmpl, gPath); err != nil {
-		logger.Fatal(err)
+		panic(err)
 	}
 
 	// Extract metadata first, before stripping it
@@ -132,6 +131,19 @@ func stripMetadata(content string) string {
 	return strings.TrimSpace(remaining) + "\n"
 }
 
+// metadataLines returns the number of lines stripMetadata removes from the start of the
+// content, so that lines of the stripped template can be mapped back to the file.
+func metadataLines(content string) int {
+	stripped := strings.TrimSpace(stripMetadata(content))
+	if stripped == "" {
+		return 0
+	}
+	if i := strings.Index(content, stripped); i >= 0 {
+		return strings.Count(content[:i], "\n")
+	}
+	return 0
+}
+
 // parseMetadataBlock parses metadata from a comment block at the start of a template
 func parseMetadataBlock(content, reason string) *TemplateMetadata {
 	switch reason {
@@ -185,7 +197,7 @@ func (m *TemplateMetadata) processPath() string {
 	dest = strings.ReplaceAll(dest, "[[group]]", Lower(groupTag))
 	dest = strings.ReplaceAll(dest, "[[reason]]", Lower(reason))
 
-	return dest
+	return inOutputRoot(dest)
 }
 
 var (
@@ -205,7 +217,7 @@ func setRootFolder(folder string) {
 
 func getTemplatePath() (string, error) {
 	templatesPathOnce.Do(func() {
-		if envPath := os.Getenv("TB_TEMPLATES_PATH"); envPath != "" {
+		if envPath := getTemplatesPathSetting(); envPath != "" {
 			if !strings.HasSuffix(envPath, "/") {
 				envPath += "/"
 			}
@@ -236,6 +248,7 @@ func getTemplatePath() (string, error) {
 		}
 
 		for _, thePath := range paths {
+			thePath = inOutputRoot(thePath)
 			classDefPath := filepath.Join(thePath, "classDefinitions")
 			if file.FolderExists(classDefPath) {
 				if strings.HasSuffix(thePath, "/templates") {
@@ -264,15 +277,15 @@ func getTemplatePathNoErr() string {
 
 // getGeneratorsPath returns the path to the generators folder, checking for TB_GENERATORS_PATH override
 func getGeneratorsPath() string {
-	if envPath := os.Getenv("TB_GENERATORS_PATH"); envPath != "" {
+	if envPath := getGeneratorsPathSetting(); envPath != "" {
 		if !strings.HasSuffix(envPath, "/") {
 			envPath += "/"
 		}
 		if !file.FolderExists(envPath) {
-			logger.Fatal(fmt.Sprintf("TB_GENERATORS_PATH env variable points to non-existent folder: %s", envPath))
+			fail("TB_GENERATORS_PATH env variable points to non-existent folder: %s", envPath)
 		}
 		if !strings.HasSuffix(envPath, "generators/") {
-			logger.Fatal(fmt.Sprintf("TB_GENERATORS_PATH must end with 'generators', got: %s", envPath))
+			fail("TB_GENERATORS_PATH must end with 'generators', got: %s", envPath)
 		}
 		return envPath
 	}
@@ -284,20 +297,20 @@ func getGeneratorsPath() string {
 	}
 
 	for _, genPath := range paths {
-		if file.FolderExists(genPath) {
+		if genPath = inOutputRoot(genPath); file.FolderExists(genPath) {
 			return genPath
 		}
 	}
 
-	logger.Fatal(fmt.Sprintf("could not find generators directory in any of: %s", strings.Join(paths, ", ")))
+	fail("could not find generators directory in any of: %s", strings.Join(paths, ", "))
 	return "" // unreachable but needed for compilation
 }
 
 func getTemplateContents(fnIn string) string {
-	fn := filepath.Join(getTemplatePathNoErr(), fnIn+".md")
+	fn := templateFile(fnIn + ".md")
 	content := file.AsciiFileToString(fn)
 	if err := ValidateTemplate(content, fn); err != nil {
-		logger.Fatal(err)
+		panic(err)
 	}
 	return content
 }
@@ -2,8 +2,6 @@ package types
 
 import (
 	"strings"
-
-	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/logger"
 )
 
 func MarkdownTable(header []string, rows [][]string) string {
@@ -30,7 +28,7 @@ func dividerRow(wids []int) string {
 
 func markdownRow(row []string, wids []int) string {
 	if len(row) != len(wids) {
-		logger.Fatal("values and wids have different lengths")
+		fail("values and wids have different lengths")
 	}
 
 	ret := []string{}
@@ -49,7 +47,7 @@ func widths(header []string, rows [][]string) []int {
 		wids[i] = max(wids[i], len(header[i]))
 		for j := 0; j < len(rows); j++ {
 			if len(rows[j]) != nFields {
-				logger.Fatal("fields[j] has the wrong number of fields")
+				fail("fields[j] has the wrong number of fields")
 			}
 			wids[i] = max(wids[i], len(rows[j][i]))
 		}
@@ -2,23 +2,224 @@ package types
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
 
 	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/file"
-	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/logger"
 )
 
+// enumValidators are the functions of chifra's validate package that check the value of
+// an enum option. Their arguments are the option's name, its value, and the valid values.
+var enumValidators = map[string]bool{
+	"ValidateEnum":         true,
+	"ValidateEnumRequired": true,
+	"ValidateEnumSlice":    true,
+}
+
+// enumValidator is a call to one of the enumValidators in a route's validate.go.
+type enumValidator struct {
+	call   string   // the function called
+	field  string   // the option's name as given to the call
+	opts   string   // the field of the options the call checks (if any)
+	values []string // the valid values (nil if they are not a string constant)
+	pos    Position // the position of the valid values
+}
+
+// VerifyValidators checks the enum validators in each route's hand-written validate.go (see
+// verifyValidators). It is not part of loading, so a stale validate.go does not stop the
+// model from loading. It returns the problems found (which are also added to Diagnostics)
+// and an error if any of them is an error (or, with warnings-as-errors, a warning).
+func (cb *CodeBase) VerifyValidators() ([]Diagnostic, error) {
+	_, start := diagnosticsSince(0)
+	cb.verifyValidators()
+	found, _ := diagnosticsSince(start)
+
+	nErrors, nWarnings := 0, 0
+	for _, d := range found {
+		if d.Severity == SeverityError {
+			nErrors++
+		} else {
+			nWarnings++
+		}
+	}
+	if nErrors > 0 {
+		return found, fmt.Errorf("found %d error(s) and %d warning(s) in the validators", nErrors, nWarnings)
+	}
+	if warningsAsErrors && nWarnings > 0 {
+		return found, fmt.Errorf("found %d warning(s) in the validators (warnings are treated as errors)", nWarnings)
+	}
+	return found, nil
+}
+
+// verifyValidators checks the enum validators in each route's hand-written validate.go
+// against the route's options. Every enum option must be validated with exactly its values
+// (in any order). A validator for an option that is not an enum of the route is a warning.
 func (cb *CodeBase) verifyValidators() {
-	cwd, _ := os.Getwd()
-	for _, cmd := range cb.Commands {
-		path := filepath.Join(cwd, "chifra/internal/", cmd.Route, "validate.go")
-		if file.FileExists(path) {
-			for _, opts := range cmd.Options {
-				if ok, wanted := ValidateEnums(path, opts.Enums); !ok {
-					logger.Fatal(fmt.Sprintf("Missing enum validator (%s) for %s", wanted, path))
+	for _, c := range cb.Commands {
+		if c.Route == "" {
+			continue
+		}
+		path := getValidatorPath(c.Route)
+		if !file.FileExists(path) {
+			continue
+		}
+
+		validators, err := findEnumValidators(path)
+		if err != nil {
+			reportError(Position{File: path}, "could not parse the validators of %s: %v", c.Route, err)
+			continue
+		}
+
+		validated := map[string]bool{}
+		for _, v := range validators {
+			op := c.validatedOption(v)
+			if op == nil {
+				reportWarning(v.pos, "%s validates %s, which is not an enum option of %s", v.call, v.field, c.Route)
+				continue
+			}
+			validated[op.LongName] = true
+			if v.values == nil {
+				reportWarning(v.pos, "the values %s validates for --%s in %s are not a string constant and cannot be checked", v.call, op.LongName, c.Route)
+				continue
+			}
+			missing, extra := compareEnums(op.Enums, v.values)
+			if len(missing) > 0 || len(extra) > 0 {
+				problems := []string{}
+				if len(missing) > 0 {
+					problems = append(problems, "missing "+strings.Join(missing, ", "))
+				}
+				if len(extra) > 0 {
+					problems = append(problems, "extra "+strings.Join(extra, ", "))
+				}
+				reportError(v.pos, "the enum validator for --%s in %s is out of date (%s); the values are [%s] (%s)", op.LongName, c.Route, strings.Join(problems, "; "), strings.Join(op.Enums, "|"), op.pos)
+			}
+		}
+
+		for _, op := range c.Options {
+			if len(op.Enums) > 0 && !validated[op.LongName] {
+				want := "ValidateEnum"
+				if op.DataType == "list<enum>" {
+					want = "ValidateEnumSlice"
+				}
+				reportError(op.pos.withKey("data_type"), "--%s in %s has no enum validator in %s (expected validate.%s with [%s])", op.LongName, c.Route, cleanOutputPath(path), want, strings.Join(op.Enums, "|"))
+			}
+		}
+	}
+}
+
+// validatedOption returns the enum option an enum validator checks. The name given to the
+// validator is preferred. If it is not an option (validators use both 'mode' and '--mode'
+// and sometimes a different name altogether), the field of the options it checks is used.
+func (c *Command) validatedOption(v enumValidator) *Option {
+	name := strings.TrimLeft(v.field, "-")
+	for i := range c.Options {
+		if op := &c.Options[i]; len(op.Enums) > 0 && op.LongName == name {
+			return op
+		}
+	}
+	for i := range c.Options {
+		if op := &c.Options[i]; len(op.Enums) > 0 && v.opts != "" && op.GoName == v.opts {
+			return op
+		}
+	}
+	return nil
+}
+
+// findEnumValidators parses the Go file at path and returns its calls to the enumValidators.
+// The valid values may be a string literal or the name of a string constant or variable
+// assigned a literal in the same file.
+func findEnumValidators(path string) ([]enumValidator, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	literals := map[string]string{}
+	assign := func(name *ast.Ident, value ast.Expr) {
+		if s, ok := stringLiteral(value); ok {
+			literals[name.Name] = s
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ValueSpec:
+			for i := range n.Names {
+				if i < len(n.Values) {
+					assign(n.Names[i], n.Values[i])
+				}
+			}
+		case *ast.AssignStmt:
+			for i := range n.Lhs {
+				if id, ok := n.Lhs[i].(*ast.Ident); ok && i < len(n.Rhs) {
+					assign(id, n.Rhs[i])
 				}
 			}
 		}
+		return true
+	})
+
+	ret := []enumValidator{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 3 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !enumValidators[sel.Sel.Name] {
+			return true
+		}
+		if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "validate" {
+			return true
+		}
+
+		v := enumValidator{call: sel.Sel.Name}
+		v.field, _ = stringLiteral(call.Args[0])
+		if field, ok := call.Args[1].(*ast.SelectorExpr); ok {
+			v.opts = field.Sel.Name
+		}
+		valid, ok := stringLiteral(call.Args[2])
+		if id, isIdent := call.Args[2].(*ast.Ident); isIdent {
+			valid, ok = literals[id.Name]
+		}
+		if ok {
+			for _, value := range strings.Split(strings.Trim(strings.TrimSpace(valid), "[]"), "|") {
+				v.values = append(v.values, strings.TrimSpace(value))
+			}
+		}
+		p := fset.Position(call.Args[2].Pos())
+		v.pos = Position{File: path, Line: p.Line, Column: p.Column}
+		ret = append(ret, v)
+		return true
+	})
+	return ret, nil
+}
+
+// stringLiteral returns the value of a (quoted or raw) string literal.
+func stringLiteral(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// compareEnums returns the values of want that are not in have and those of have that
+// are not in want.
+func compareEnums(want, have []string) (missing, extra []string) {
+	for _, w := range want {
+		if !contains(have, w) {
+			missing = append(missing, w)
+		}
+	}
+	for _, h := range have {
+		if !contains(want, h) {
+			extra = append(extra, h)
+		}
 	}
+	return missing, extra
 }
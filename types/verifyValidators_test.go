@@ -0,0 +1,84 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindEnumValidators(t *testing.T) {
+	src := `package status
+
+// validate.ValidateEnum("--stale", opts.Stale, "[a|b]")
+func (opts *StatusOptions) validateStatus() error {
+	options := ` + "`[index | blooms|some|all]`" + `
+	if err := validate.ValidateEnumSlice("mode", opts.Modes, options); err != nil {
+		return err
+	}
+	if err := validate.ValidateEnumRequired("--kind", opts.Kind, "[x|y]"); err != nil {
+		return err
+	}
+	_ = other.ValidateEnum("--other", opts.Other, "[z]")
+	return validate.ValidateEnum("--fmt", opts.Format, valid())
+}
+`
+	path := filepath.Join(t.TempDir(), "validate.go")
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := findEnumValidators(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []enumValidator{
+		{call: "ValidateEnumSlice", field: "mode", opts: "Modes", values: []string{"index", "blooms", "some", "all"}, pos: Position{File: path, Line: 6, Column: 59}},
+		{call: "ValidateEnumRequired", field: "--kind", opts: "Kind", values: []string{"x", "y"}, pos: Position{File: path, Line: 9, Column: 63}},
+		{call: "ValidateEnum", field: "--fmt", opts: "Format", pos: Position{File: path, Line: 13, Column: 53}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestCompareEnums(t *testing.T) {
+	missing, extra := compareEnums([]string{"in", "out", "zero"}, []string{"zero", "in", "bogus"})
+	if !reflect.DeepEqual(missing, []string{"out"}) || !reflect.DeepEqual(extra, []string{"bogus"}) {
+		t.Errorf("got missing %v and extra %v", missing, extra)
+	}
+	if missing, extra := compareEnums([]string{"a", "b"}, []string{"b", "a"}); missing != nil || extra != nil {
+		t.Errorf("reordered values should match, got missing %v and extra %v", missing, extra)
+	}
+}
+
+// TestStaleValidator makes sure a stale validate.go does not stop the model from loading
+// but does stop generation.
+func TestStaleValidator(t *testing.T) {
+	cb, templates := loadTemplatesCopy(t)
+	validator := filepath.Join(filepath.Dir(templates), "chifra", "internal", "config", "validate.go")
+	writeFile(t, validator, `package config
+
+func (opts *ConfigOptions) validateConfig() error {
+	return validate.ValidateEnum("mode", opts.Mode, "[show|bogus]")
+}
+`)
+
+	ResetDiagnostics()
+	if _, err := LoadCodebase(); err != nil {
+		t.Fatalf("a stale validate.go should not stop the model from loading: %v", err)
+	}
+
+	found, err := cb.VerifyValidators()
+	if err == nil {
+		t.Fatal("expected the stale validator to be an error")
+	}
+	if len(found) != 1 || found[0].File != validator || !strings.Contains(found[0].Message, "the enum validator for --mode in config is out of date") {
+		t.Errorf("unexpected diagnostics: %v", found)
+	}
+
+	if err := cb.GenerateOnly(nil); err == nil || !strings.Contains(err.Error(), "in the validators") {
+		t.Errorf("expected generation to stop, got %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+package types
+
+import (
+	"encoding/json"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Snapshot records the modification time and size of every file in the templates and
+// generators folders. Comparing two snapshots tells us which inputs have changed.
+type Snapshot map[string]fileStamp
+
+// TakeSnapshot returns a Snapshot of the templates and generators folders (and any
+// template overlays).
+func TakeSnapshot() Snapshot {
+	ret := Snapshot{}
+	folders := []string{}
+	for _, layer := range generatorLayers() {
+		folders = append(folders, layer.templates, layer.generators)
+	}
+	for _, folder := range folders {
+		root, err := filepath.Abs(folder)
+		if err != nil {
+			continue
+		}
+		_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || strings.HasSuffix(path, ".tmp") {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				ret[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+			}
+			return nil
+		})
+	}
+	return ret
+}
+
+// Changed returns the (sorted) paths that were added, removed, or modified between
+// this snapshot and the later one.
+func (s Snapshot) Changed(later Snapshot) []string {
+	ret := []string{}
+	for path, stamp := range later {
+		if prev, ok := s[path]; !ok || !prev.modTime.Equal(stamp.modTime) || prev.size != stamp.size {
+			ret = append(ret, path)
+		}
+	}
+	for path := range s {
+		if _, ok := later[path]; !ok {
+			ret = append(ret, path)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// NeedsReload returns true if any of the changed paths is part of the data model (that is,
+// the class definitions, the command line options, or the base types).
+func NeedsReload(changed []string) bool {
+	for _, path := range changed {
+		if rel, ok := templateRel(path); ok && isModelInput(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+func isModelInput(rel string) bool {
+	return strings.HasPrefix(rel, "classDefinitions/") || rel == "cmd-line-options.csv" || rel == "base-types.csv"
+}
+
+// AffectedBy returns the outputs that need to be regenerated given the changed paths.
+// previous is the codebase as it was before the changes (it may be the same as cb if
+// the data model did not change). A nil return means everything is affected.
+func (cb *CodeBase) AffectedBy(previous *CodeBase, changed []string) (targets *Targets) {
+	var err error
+	defer func() {
+		if err != nil {
+			targets = nil
+		}
+	}()
+	defer recoverError(&err)
+
+	targets = &Targets{
+		Templates: map[string]bool{},
+		Groups:    map[string]bool{},
+		Routes:    map[string]bool{},
+		Types:     map[string]bool{},
+	}
+
+	addRoute := func(route string) {
+		targets.Routes[route] = true
+		for _, c := range cb.Commands {
+			if c.Route == route {
+				targets.Groups[c.GroupName()] = true
+			}
+		}
+		targets.Codebase = true
+	}
+	addType := func(s *Structure) {
+		targets.Types[s.Class] = true
+		targets.Groups[s.GroupName()] = true
+		targets.Codebase = true
+	}
+
+	modelChanged := false
+	for _, path := range changed {
+		if rel, ok := generatorRel(path); ok {
+			parts := strings.SplitN(rel, "/", 2)
+			if len(parts) < 2 || strings.HasSuffix(rel, ".partial.tmpl") {
+				// A partial (or something we don't understand) may be used by any template in the folder
+				generators, err := getGenerators()
+				if err != nil {
+					return nil
+				}
+				for _, g := range generators {
+					if g.Against == parts[0] || len(parts) < 2 {
+						for _, source := range g.Templates {
+							targets.Templates[getGeneratorPath(g.Against, source)] = true
+						}
+					}
+				}
+			} else {
+				// An overlay may replace (or, if removed, no longer replace) the template
+				targets.Templates[getGeneratorPath(parts[0], parts[1])] = true
+			}
+			continue
+		}
+
+		rel, ok := templateRel(path)
+		if !ok {
+			return nil
+		}
+
+		name := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(rel), ".md"), ".notes")
+		switch {
+		case isModelInput(rel):
+			modelChanged = true
+		case strings.HasPrefix(rel, "readme-intros/"):
+			found := false
+			for _, c := range cb.Commands {
+				if c.Route != "" && c.Route == name {
+					addRoute(c.Route)
+					found = true
+				}
+			}
+			if !found { // for example, README.footer.md
+				for _, c := range cb.Commands {
+					if c.Route != "" {
+						addRoute(c.Route)
+					}
+				}
+			}
+		case strings.HasPrefix(rel, "model-intros/"):
+			found := false
+			for i := range cb.Structures {
+				if CamelCase(cb.Structures[i].Class) == name {
+					addType(&cb.Structures[i])
+					found = true
+				}
+			}
+			if !found {
+				for i := range cb.Structures {
+					addType(&cb.Structures[i])
+				}
+			}
+		case strings.HasPrefix(rel, "readme-groups/"), strings.HasPrefix(rel, "model-groups/"):
+			for _, g := range cb.GroupList("") {
+				targets.Groups[g.GroupName()] = true
+			}
+			targets.Codebase = true
+		default:
+			return nil
+		}
+	}
+
+	if modelChanged {
+		// Compare each command and structure to its previous version
+		before := map[string]string{}
+		for _, c := range previous.Commands {
+			before["route:"+c.Route] = asJson(c)
+		}
+		for _, s := range previous.Structures {
+			before["type:"+s.Class] = s.String()
+		}
+		for _, c := range cb.Commands {
+			if c.Route != "" && before["route:"+c.Route] != asJson(c) {
+				addRoute(c.Route)
+			}
+		}
+		for i := range cb.Structures {
+			if before["type:"+cb.Structures[i].Class] != cb.Structures[i].String() {
+				addType(&cb.Structures[i])
+			}
+		}
+		targets.Codebase = true
+	}
+
+	return targets
+}
+
+func relativeTo(folder, path string) (string, bool) {
+	root, err := filepath.Abs(folder)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+func asJson(v any) string {
+	bytes, _ := json.Marshal(v)
+	return string(bytes)
+}
+
+// WatchInterval is how often Watch polls the templates folder for changes.
+var WatchInterval = 500 * time.Millisecond
+
+// Watch polls the templates and generators folders. Each time something changes, it calls
+// onChange with the list of changed paths. It never returns.
+func Watch(onChange func(changed []string)) {
+	last := TakeSnapshot()
+	for {
+		time.Sleep(WatchInterval)
+		current := TakeSnapshot()
+		if changed := last.Changed(current); len(changed) > 0 {
+			// Anything that changes while we are busy is picked up on the next pass
+			onChange(changed)
+		}
+		last = current
+	}
+}
@@ -0,0 +1,167 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// loadTemplatesCopy loads the codebase from a copy of the repository's templates folder
+// in a dry run and returns it along with the copy's path.
+func loadTemplatesCopy(t *testing.T) (CodeBase, string) {
+	t.Helper()
+	dir := t.TempDir()
+	templates := copyTemplates(t, dir)
+	SetTemplatesPath(templates)
+	SetGeneratorsPath(filepath.Join(templates, "generators"))
+	SetOutputRoot(dir)
+	SetDryRun(true)
+	t.Cleanup(func() {
+		SetTemplatesPath("")
+		SetGeneratorsPath("")
+		SetOutputRoot("")
+		SetDryRun(false)
+		ResetPendingChanges()
+		ResetDiagnostics()
+	})
+
+	cb, err := LoadCodebase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cb, templates
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSnapshotChanged(t *testing.T) {
+	_, templates := loadTemplatesCopy(t)
+
+	before := TakeSnapshot()
+	if len(before) == 0 {
+		t.Fatal("the snapshot is empty")
+	}
+	if changed := before.Changed(TakeSnapshot()); len(changed) != 0 {
+		t.Errorf("expected no changes, got %v", changed)
+	}
+
+	modified := filepath.Join(templates, "readme-intros", "blocks.md")
+	added := filepath.Join(templates, "generators", "routes", "new.md.tmpl")
+	removed := filepath.Join(templates, "model-intros", "abi.md")
+	writeFile(t, modified, "changed\n")
+	writeFile(t, added, "new\n")
+	writeFile(t, added+".tmp", "editor scratch files are ignored\n")
+	if err := os.Remove(removed); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{modified, added, removed}
+	sort.Strings(want)
+	if changed := before.Changed(TakeSnapshot()); !reflect.DeepEqual(changed, want) {
+		t.Errorf("got %v, want %v", changed, want)
+	}
+}
+
+func TestAffectedBy(t *testing.T) {
+	cb, templates := loadTemplatesCopy(t)
+	writeFile(t, filepath.Join(templates, "generators", "types", "fields.partial.tmpl"), "{{.Class}}")
+
+	var block *Structure
+	for i := range cb.Structures {
+		if cb.Structures[i].Class == "Block" {
+			block = &cb.Structures[i]
+		}
+	}
+	if block == nil {
+		t.Fatal("no Block structure")
+	}
+
+	// An edited class definition affects only the structures that changed
+	edited := cb
+	edited.Structures = append([]Structure{}, cb.Structures...)
+	for i := range edited.Structures {
+		if edited.Structures[i].Class == "Block" {
+			edited.Structures[i].DocDescr = "changed"
+		}
+	}
+
+	routeTemplate := getGeneratorPath("routes", "src_dev+tools_goMaker_generated_readme+route.md.tmpl")
+	typeTemplate := getGeneratorPath("types", "src_dev+tools_goMaker_generated_model+type.md.tmpl")
+	tests := []struct {
+		name      string
+		current   *CodeBase
+		changed   string
+		templates []string
+		routes    []string
+		types     []string
+	}{
+		{name: "route intro", changed: "readme-intros/blocks.md", routes: []string{"blocks"}},
+		{name: "route notes", changed: "readme-intros/blocks.notes.md", routes: []string{"blocks"}},
+		{name: "type intro", changed: "model-intros/block.md", types: []string{"Block"}},
+		{name: "template", changed: "generators/routes/src_dev+tools_goMaker_generated_readme+route.md.tmpl", templates: []string{routeTemplate}},
+		{name: "partial", changed: "generators/types/fields.partial.tmpl", templates: []string{typeTemplate}},
+		{name: "class definition", current: &edited, changed: "classDefinitions/block.toml", types: []string{"Block"}},
+	}
+
+	keys := func(m map[string]bool) []string {
+		ret := []string{}
+		for k := range m {
+			ret = append(ret, k)
+		}
+		sort.Strings(ret)
+		return ret
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := &cb
+			if tt.current != nil {
+				current = tt.current
+			}
+			targets := current.AffectedBy(&cb, []string{filepath.Join(templates, tt.changed)})
+			if targets == nil {
+				t.Fatal("expected some targets, got everything")
+			}
+			for _, check := range []struct {
+				what      string
+				got, want []string
+			}{
+				{"templates", keys(targets.Templates), tt.templates},
+				{"routes", keys(targets.Routes), tt.routes},
+				{"types", keys(targets.Types), tt.types},
+			} {
+				if check.want == nil {
+					check.want = []string{}
+				}
+				if !reflect.DeepEqual(check.got, check.want) {
+					t.Errorf("%s: got %v, want %v", check.what, check.got, check.want)
+				}
+			}
+			if (len(tt.routes) > 0 || len(tt.types) > 0) && !targets.Codebase {
+				t.Error("a changed route or type should regenerate the codebase outputs")
+			}
+		})
+	}
+
+	if targets := cb.AffectedBy(&cb, []string{filepath.Join(templates, "unknown.txt")}); targets != nil {
+		t.Errorf("an unknown file should affect everything, got %+v", targets)
+	}
+	if targets := cb.AffectedBy(&cb, []string{filepath.Join(t.TempDir(), "elsewhere.md")}); targets != nil {
+		t.Errorf("a file outside the templates should affect everything, got %+v", targets)
+	}
+	if NeedsReload([]string{filepath.Join(templates, "readme-intros", "blocks.md")}) {
+		t.Error("an intro is not part of the model")
+	}
+	if !NeedsReload([]string{filepath.Join(templates, "cmd-line-options.csv")}) {
+		t.Error("cmd-line-options.csv is part of the model")
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/TrueBlocks/goMaker/v6/maker"
+	"github.com/TrueBlocks/goMaker/v6/types"
+	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/colors"
+	"github.com/TrueBlocks/trueblocks-chifra/v6/pkg/logger"
+)
+
+func runWatch(args []string) error {
+	fs, common := newFlagSet("watch")
+	gen := addGenerateFlags(fs)
+	interval := fs.Duration("interval", types.WatchInterval, "how often to check for changes")
+	incremental := fs.Bool("incremental", false, "skip outputs whose inputs have not changed since the last run")
+	positionals, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := noPositionals(fs.Name(), positionals); err != nil {
+		return err
+	}
+	opts := maker.Options{Incremental: *incremental}
+	common.apply(&opts)
+	gen.apply(&opts)
+	// While iterating, the codebase snapshot changes all the time. Don't stop because of it.
+	opts.RemoteTesting = true
+
+	generator, err := loadCodebase(opts)
+	if err != nil {
+		return err
+	}
+	codeBase := generator.CodeBase()
+
+	types.WatchInterval = *interval
+	logger.Info(colors.Green + "Watching for changes (Ctrl+C to quit)..." + colors.Off)
+	types.Watch(func(changed []string) {
+		for _, path := range changed {
+			rel, _ := filepath.Rel(mustGetwd(), path)
+			logger.Info("Changed:", rel)
+		}
+
+		previous := *codeBase
+		if types.NeedsReload(changed) {
+			err := generator.Load()
+			showDiagnostics(generator.Diagnostics())
+			if err != nil {
+				reportWatchError(err)
+				return
+			}
+			codeBase = generator.CodeBase()
+		}
+
+		targets := codeBase.AffectedBy(&previous, changed)
+		if targets != nil && targets.IsEmpty() {
+			logger.Info("Nothing to regenerate.")
+			return
+		}
+
+		start := time.Now()
+		types.ResetTemplateCache()
+		if err := generator.GenerateOnly(targets); err != nil {
+			reportWatchError(err)
+			return
+		}
+		logger.Info(fmt.Sprintf("Regenerated in %s", time.Since(start).Round(time.Millisecond)))
+	})
+	return nil
+}
+
+func reportWatchError(err error) {
+	logger.Error(colors.Red + err.Error() + colors.Off)
+	logger.Info("Still watching. Fix the error and save again.")
+}
+
+func mustGetwd() string {
+	cwd, _ := os.Getwd()
+	return cwd
+}